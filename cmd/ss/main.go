@@ -4,10 +4,75 @@
 // Incoming signals will be printed to the standard output,
 // followed by a line break after each signal.
 // The size of a signal must be between 1 and 1024 bytes.
+// In line mode, each line of the input is sent as its own signal,
+// packed together into as few datagrams as possible.
 //
 // Usage:
 //
-//	stdin | ss [relay] > stdout
+//	stdin | ss [-t topic] [-r retention] [-k key] [-i id] [-L] [-s since] [-m key=value]... [-f filter]... [-c count] [-b bytes] [-l lease] [-H] [relay] > stdout
+//	ss -a operation [-t topic] [-n state] [-d target] [relay] > stdout
+//
+// The flags are:
+//
+//	-t topic
+//		Name of the topic to send or scan signals.
+//		Defaults to the default topic.
+//
+//	-r retention
+//		Retention time of sent signals in seconds.
+//		Defaults to the retention time of the server.
+//
+//	-k key
+//		Key of sent signals, superseding older signals with the
+//		same key, if the server is compacted.
+//
+//	-i id
+//		Producer id of the sent signal, dropping it as a duplicate,
+//		if the server is deduplicated. Not used in line mode.
+//
+//	-L
+//		Send each line of the input as its own signal.
+//
+//	-s since
+//		Scan signals received in the last seconds.
+//		Defaults to all new signals.
+//
+//	-m key=value
+//		Header of sent signals. Can be given multiple times.
+//
+//	-f filter
+//		Filter term of scanned signals. Can be given multiple times,
+//		a signal must then match all terms. One of prefix:bytes,
+//		contains:bytes, regex:expr, header:key=value, from:ms or to:ms.
+//
+//	-c count
+//		Maximum count of scanned signals.
+//		Defaults to no limit.
+//
+//	-b bytes
+//		Maximum size of scanned signals in bytes.
+//		Defaults to no limit.
+//
+//	-l lease
+//		Lease time of scanned signals in seconds. All scanned signals
+//		will be acknowledged after they have been printed. Signals not
+//		acknowledged within the lease time will be scanned again.
+//		Defaults to no lease.
+//
+//	-H
+//		Print the header of scanned signals, one line per
+//		key value pair, before the signal.
+//
+//	-a operation
+//		Admin operation on a state, instead of sending or scanning.
+//		One of list, inspect, delete, copy or rename.
+//
+//	-n state
+//		Name of the state for the admin operation.
+//		Defaults to the state of this client.
+//
+//	-d target
+//		Name of the target state, required for copy and rename.
 //
 // The arguments are:
 //
@@ -17,38 +82,147 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cuhsat/subspace/internal/app/ss"
 	"github.com/cuhsat/subspace/internal/pkg/sys"
+	"github.com/cuhsat/subspace/internal/pkg/wire"
+	"github.com/cuhsat/subspace/pkg/sub"
 )
 
 // The main function will open a channel to subspace relay
 // and will either send or scan signals, dependent on
 // there is data to be read from the standard input.
 func main() {
+	topic := flag.String("t", "", "topic name")
+	rt := flag.Int64("r", 0, "retention time in seconds")
+	key := flag.String("k", "", "key for compaction")
+	id := flag.String("i", "", "producer id for deduplication")
+	lines := flag.Bool("L", false, "send each line as a signal")
+	since := flag.Int64("s", 0, "scan signals of the last seconds")
+	op := flag.String("a", "", "admin operation")
+	state := flag.String("n", "", "state name")
+	target := flag.String("d", "", "target state name")
+	count := flag.Uint64("c", 0, "maximum count of scanned signals")
+	size := flag.Uint64("b", 0, "maximum size of scanned signals")
+	lease := flag.Int64("l", 0, "lease time in seconds")
+	headers := flag.Bool("H", false, "print headers")
+
+	h := make(map[string]string)
+
+	var fs [][]byte
+
+	flag.Func("f", "filter term as kind:value", func(v string) error {
+		if _, err := sub.ParseFilter(v); err != nil {
+			return err
+		}
+
+		fs = append(fs, []byte(v))
+
+		return nil
+	})
+
+	flag.Func("m", "header as key=value", func(v string) error {
+		k, v, ok := strings.Cut(v, "=")
+
+		if !ok {
+			return errors.New("missing =")
+		}
+
+		h[k] = v
+
+		return nil
+	})
+
+	flag.Parse()
+
 	relay := "localhost"
 
-	if len(os.Args) > 1 {
-		relay = os.Args[1]
+	if flag.NArg() > 0 {
+		relay = flag.Arg(0)
 	}
 
 	c := ss.NewChannel(relay)
 
-	b := sys.Stdin()
+	if len(*topic) > 0 {
+		c.Topic = []byte(*topic)
+	}
 
-	if len(b) > sys.MaxBuffer {
-		sys.Fatal("buffer overflow")
-	} else if len(b) > 0 {
-		c.Send(b)
-	} else {
+	c.TTL = *rt * 1e3
+
+	if len(*key) > 0 {
+		c.Key = []byte(*key)
+	}
+
+	if len(*id) > 0 {
+		c.ID = []byte(*id)
+	}
+	c.Filter = fs
+	c.Count, c.Size = *count, *size
+	c.Lease = *lease * 1e3
+
+	if *since > 0 {
+		c.Since = time.Now().UnixMilli() - *since*1e3
+	}
+
+	if (*op == wire.OpCopy || *op == wire.OpRename) && len(*target) == 0 {
+		sys.Fatal("missing target")
+	}
+
+	if len(*op) > 0 {
 		ch := make(chan []byte)
 
-		go c.Scan(ch, sys.Address())
+		n := sys.Address()
+
+		if len(*state) > 0 {
+			n = []byte(*state)
+		}
+
+		go c.Admin(ch, *op, n, []byte(*target))
 
 		for v := range ch {
 			fmt.Println(string(v))
 		}
+
+		return
+	}
+
+	b := sys.Stdin()
+
+	if *lines && len(b) > 0 {
+		l := bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n"))
+
+		c.SendBatch(l, h)
+	} else if len(b) > 0 {
+		c.SendHeader(b, h)
+	} else {
+		ch := make(chan *wire.Frame)
+
+		go c.ScanFrames(ch, sys.Address())
+
+		var last uint64
+
+		for f := range ch {
+			if *headers {
+				for _, k := range slices.Sorted(maps.Keys(f.Header)) {
+					fmt.Printf("%s: %s\n", k, f.Header[k])
+				}
+			}
+
+			fmt.Println(string(f.Data))
+
+			last = max(last, f.Offset)
+		}
+
+		if c.Lease > 0 && last > 0 {
+			c.Ack(sys.Address(), last)
+		}
 	}
 }
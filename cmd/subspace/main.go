@@ -18,15 +18,23 @@
 //
 // For configuration, values can be set via environment variables:
 //   - SUBSPACE_RETENTION for retention time in seconds.
+//   - SUBSPACE_JOURNAL for the journal directory, if signals should be durable.
+//   - SUBSPACE_SNAPSHOT for the snapshot file, loaded on start and written on exit.
+//     If the journal already restored signals, the journal wins and the snapshot is not loaded.
+//   - SUBSPACE_MAX_COUNT for the maximum count of signals of all topics.
+//   - SUBSPACE_MAX_SIZE for the maximum allocated memory of all topics in bytes.
+//   - SUBSPACE_POLICY for the policy if a maximum is reached (evict or reject).
+//   - SUBSPACE_COMPACT for the key-based compaction mode, if set.
+//   - SUBSPACE_DEDUP for the window in seconds, in which duplicate signals are dropped.
 package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
-	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -37,7 +45,7 @@ import (
 
 // The main function will create a new subspace and binds it to two routines,
 // waiting for incoming pseudo-connections to send or scan signals.
-// It will run its own signal garbage collection periodic in the background.
+// The subspace will drop its signals periodic in the background.
 func main() {
 	rt := int(time.Hour / 1e9)
 
@@ -49,7 +57,43 @@ func main() {
 		go subspace.Relay(os.Args[1:])
 	}
 
-	s := sub.NewSpace()
+	opts := limit()
+
+	if rt > 0 {
+		opts = append(opts, sub.WithRetention(int64(rt)*1e3), sub.WithAutoDrop(time.Second))
+	}
+
+	if e, ok := os.LookupEnv("SUBSPACE_DEDUP"); ok {
+		w, err := strconv.ParseInt(e, 10, 64)
+
+		if err != nil {
+			sys.Fatal(err)
+		}
+
+		opts = append(opts, sub.WithDedup(w*1e3))
+	}
+
+	var s *sub.Space
+
+	if e, ok := os.LookupEnv("SUBSPACE_JOURNAL"); ok {
+		var err error
+
+		if s, err = sub.Open(e, opts...); err != nil {
+			sys.Fatal(err)
+		}
+	} else {
+		s = sub.NewSpace(opts...)
+	}
+
+	if _, ok := os.LookupEnv("SUBSPACE_COMPACT"); ok {
+		s.Compact(true)
+	}
+
+	snap, ok := os.LookupEnv("SUBSPACE_SNAPSHOT")
+
+	if ok {
+		load(s, snap)
+	}
 
 	exit := make(chan os.Signal, 1)
 
@@ -58,12 +102,20 @@ func main() {
 	go bind(s, subspace.Send, sys.Port1)
 	go bind(s, subspace.Scan, sys.Port2)
 
-	go gc(s, rt)
+	go stats(s)
 
 	fmt.Printf("⇌ Subspace %ds %v\n", rt, os.Args[1:])
 
 	<-exit
 
+	if ok {
+		save(s, snap)
+	}
+
+	if err := s.Close(); err != nil {
+		sys.Error(err)
+	}
+
 	fmt.Printf("⇌ Subspace lost\n")
 }
 
@@ -79,24 +131,92 @@ func bind(s *sub.Space, fn subspace.Bind, addr string) {
 	}
 }
 
-// GC triggers the subspace garbage collection per drop every second
-// and logs stats about the space and its traffic as JSON
-// to the stats output, overwriting it each time.
-func gc(s *sub.Space, rt int) {
-	for range time.Tick(time.Second) {
-		if rt > 0 {
-			s.Drop(int64(rt) * 1e3)
+// Limit returns the limit options of the subspace as configured.
+// The limits apply to all topics together.
+//
+// Any calling program will terminate immediately if an error occurs.
+func limit() []sub.Option {
+	var mc, ms uint64
+	var err error
+
+	if e, ok := os.LookupEnv("SUBSPACE_MAX_COUNT"); ok {
+		if mc, err = strconv.ParseUint(e, 10, 64); err != nil {
+			sys.Fatal(err)
 		}
+	}
 
-		j, err := json.Marshal(struct {
-			Num, Mem, Rx, Tx, Fx uint64
-		}{
-			atomic.LoadUint64(&s.StatCount),
-			atomic.LoadUint64(&s.StatAlloc),
-			atomic.LoadUint64(&subspace.Rx),
-			atomic.LoadUint64(&subspace.Tx),
-			atomic.LoadUint64(&subspace.Fx),
-		})
+	if e, ok := os.LookupEnv("SUBSPACE_MAX_SIZE"); ok {
+		if ms, err = strconv.ParseUint(e, 10, 64); err != nil {
+			sys.Fatal(err)
+		}
+	}
+
+	p := sub.Evict
+
+	switch e := os.Getenv("SUBSPACE_POLICY"); e {
+	case "", "evict":
+	case "reject":
+		p = sub.Reject
+	default:
+		sys.Fatal("unknown policy", e) // blocking would park a goroutine per datagram
+	}
+
+	return []sub.Option{sub.WithTotal(mc, ms), sub.WithLimit(0, 0, p)}
+}
+
+// Load restores the given subspace from the snapshot file, if it exists.
+//
+// Any calling program will terminate immediately if an error occurs.
+func load(s *sub.Space, name string) {
+	f, err := os.Open(name)
+
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		sys.Fatal(err)
+	}
+
+	defer f.Close()
+
+	// the journal wins over the snapshot
+	if err = s.Restore(f); errors.Is(err, sub.ErrNotEmpty) {
+		sys.Error("snapshot not loaded:", err)
+	} else if err != nil {
+		sys.Fatal(err)
+	}
+}
+
+// Save writes a snapshot of the given subspace to the snapshot file.
+// The snapshot is written to a temporary file first, which will then
+// replace the snapshot file.
+func save(s *sub.Space, name string) {
+	f, err := os.Create(name + ".tmp")
+
+	if err != nil {
+		sys.Error(err)
+		return
+	}
+
+	err = s.Snapshot(f)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+
+	if err != nil {
+		sys.Error(err)
+	}
+}
+
+// Stats logs stats about all topics and the traffic every second as JSON
+// to the stats output, overwriting it each time.
+func stats(s *sub.Space) {
+	for range time.Tick(time.Second) {
+		j, err := json.Marshal(subspace.Stats(s))
 
 		if err == nil {
 			sys.Stats.Truncate(0)
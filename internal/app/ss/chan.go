@@ -2,6 +2,7 @@
 package ss
 
 import (
+	"errors"
 	"net"
 	"os"
 	"runtime"
@@ -9,20 +10,31 @@ import (
 	"time"
 
 	"github.com/cuhsat/subspace/internal/pkg/sys"
+	"github.com/cuhsat/subspace/internal/pkg/wire"
 )
 
 // A channel is a bi-directional communication provider for a subspace.
 type Channel struct {
-	Rx uint64       // received bytes.
-	Tx uint64       // transmitted bytes.
-	ru *net.UDPConn // receiving connection.
-	tu *net.UDPConn // transmitting connection.
+	Rx     uint64       // received bytes.
+	Tx     uint64       // transmitted bytes.
+	Topic  []byte       // addressed topic, nil for the default topic.
+	TTL    int64        // time to live of sent signals in milliseconds.
+	Key    []byte       // key of sent signals for compaction, nil for none.
+	ID     []byte       // producer id of sent signals for deduplication, nil for none.
+	Since  int64        // scan start in milliseconds since epoch, 0 for the state.
+	Filter [][]byte     // filter terms of scans, nil for all signals.
+	Offset uint64       // scan start offset, 0 for the state.
+	Count  uint64       // maximum count of scanned signals, 0 for no limit.
+	Size   uint64       // maximum size of scanned signals, 0 for no limit.
+	Lease  int64        // lease time of scanned signals in milliseconds, 0 for no lease.
+	ru     *net.UDPConn // receiving connection.
+	tu     *net.UDPConn // transmitting connection.
 }
 
 // NewChannel returns a new channel for communicating with a subspace.
 // The channel opens two UDP pseudo connections for sending and receiving
-// signals as bytes arrays. These connections will be closed automatically
-// when the channel is being freed by the garbage collector.
+// signals as bytes arrays. These connections will be closed by Close or
+// automatically when the channel is being freed by the garbage collector.
 //
 // Any calling program will terminate immediately if an error occurs.
 func NewChannel(host string) (c *Channel) {
@@ -40,11 +52,38 @@ func NewChannel(host string) (c *Channel) {
 	return
 }
 
+// Close closes both UDP pseudo connections of the channel immediately.
+// The channel must not be used afterwards.
+func (c *Channel) Close() error {
+	runtime.SetFinalizer(c, nil)
+
+	return errors.Join(c.ru.Close(), c.tu.Close())
+}
+
 // Send the given signal to the subspace via an UDP pseudo connection.
+// The signal will be framed, if the channel addresses a topic
+// or sets a time to live, key or producer id.
+// A signal exceeding the maximum buffer size once framed is fatal.
 //
 // Send will count all transmitted bytes.
 func (c *Channel) Send(b []byte) {
-	n, err := c.tu.Write(b)
+	c.SendHeader(b, nil)
+}
+
+// SendHeader sends the given signal the same way as Send,
+// but framed together with the given header, if any.
+//
+// SendHeader will count all transmitted bytes.
+func (c *Channel) SendHeader(b []byte, h map[string]string) {
+	f := wire.Frame{Topic: c.Topic, Data: b, TTL: c.TTL, Key: c.Key, ID: c.ID, Header: h}
+
+	v := f.Encode()
+
+	if len(v) > sys.MaxBuffer {
+		sys.Fatal("buffer overflow")
+	}
+
+	n, err := c.tu.Write(v)
 
 	if err != nil {
 		sys.Fatal(err)
@@ -53,24 +92,135 @@ func (c *Channel) Send(b []byte) {
 	atomic.AddUint64(&c.Tx, uint64(n))
 }
 
+// SendBatch sends the given signals the same way as SendHeader,
+// but packed into as few batch frames as possible. The signals
+// of each frame will be appended to the subspace at once. The
+// producer id of the channel will not be sent. A signal
+// exceeding the maximum buffer size once framed is fatal.
+//
+// SendBatch will count all transmitted bytes.
+func (c *Channel) SendBatch(l [][]byte, h map[string]string) {
+	f := wire.Frame{Topic: c.Topic, TTL: c.TTL, Key: c.Key, Header: h}
+
+	bs := f.Pack(l, sys.MaxBuffer)
+
+	for _, b := range bs {
+		if len(b) > sys.MaxBuffer {
+			sys.Fatal("buffer overflow")
+		}
+	}
+
+	for _, b := range bs {
+		n, err := c.tu.Write(b)
+
+		if err != nil {
+			sys.Fatal(err)
+		}
+
+		atomic.AddUint64(&c.Tx, uint64(n))
+	}
+}
+
 // Scan all new signals in a subspace via an UDP pseudo connection.
+// If the channel sets a start time, the state will be moved there first.
+// If the channel sets filter terms, only selected signals will be scanned.
+// If the channel sets limits, the scan will stop at them and the state will
+// only be advanced to the last scanned signal. If the channel sets a lease
+// time, the state will only be advanced by Ack and all signals that have not
+// been acknowledged within the lease time, will be scanned again.
 // If no further signals are received and the deadline of one second is reached,
 // we consider the scan finished. So a call has a minimum duration of one second.
+// Signals, whose frames were truncated, will be dropped.
 //
 // Scan will count all received and transmitted bytes.
 func (c *Channel) Scan(ch chan<- []byte, state []byte) {
-	n, err := c.ru.Write(state)
+	c.request(c.scanRequest(state))
+
+	c.read(func(b []byte) {
+		if f := wire.Signal(b); f != nil {
+			ch <- f.Data
+		}
+	})
+
+	close(ch)
+}
+
+// ScanFrames scans all new signals the same way as Scan, but writes
+// each signal as a frame, so that its header can be read as well.
+// The offset of each signal will only be set, if the channel sets limits
+// or a lease time.
+//
+// ScanFrames will count all received and transmitted bytes.
+func (c *Channel) ScanFrames(ch chan<- *wire.Frame, state []byte) {
+	c.request(c.scanRequest(state))
+
+	c.read(func(b []byte) {
+		if f := wire.Signal(b); f != nil {
+			ch <- f
+		}
+	})
+
+	close(ch)
+}
+
+// ScanRequest returns the scan request frame for the given state.
+func (c *Channel) scanRequest(state []byte) wire.Frame {
+	return wire.Frame{
+		Topic:  c.Topic,
+		State:  state,
+		Since:  c.Since,
+		Filter: c.Filter,
+		Offset: c.Offset,
+		Count:  c.Count,
+		Size:   c.Size,
+		Lease:  c.Lease,
+	}
+}
+
+// Ack acknowledges all signals up to the given offset for the given state
+// via an UDP pseudo connection, so that the state will be advanced. As the
+// acknowledgement will not be responded, a lost acknowledgement will lead to
+// the signals being scanned again after the lease time.
+//
+// Ack will count all transmitted bytes.
+func (c *Channel) Ack(state []byte, offset uint64) {
+	c.request(wire.Frame{Topic: c.Topic, State: state, Op: []byte(wire.OpAck), Offset: offset})
+}
+
+// Admin executes the given admin operation on a state in a subspace
+// via an UDP pseudo connection. The target is only used for copying
+// and renaming. All responses will be written to the given channel.
+// So a call has a minimum duration of one second, same as Scan.
+//
+// Admin will count all received and transmitted bytes.
+func (c *Channel) Admin(ch chan<- []byte, op string, state, target []byte) {
+	c.request(wire.Frame{Topic: c.Topic, State: state, Op: []byte(op), Target: target})
+
+	c.read(func(b []byte) {
+		ch <- b
+	})
+
+	close(ch)
+}
+
+// Request writes the given request frame to the receiving connection.
+func (c *Channel) request(f wire.Frame) {
+	n, err := c.ru.Write(f.Encode())
 
 	if err != nil {
 		sys.Fatal(err)
 	}
 
 	atomic.AddUint64(&c.Tx, uint64(n))
+}
 
+// Read passes all received datagrams to the given function,
+// until the deadline of one second is reached.
+func (c *Channel) read(fn func(b []byte)) {
 	for {
 		b := sys.NewBuffer()
 
-		c.ru.SetDeadline(time.Now().Add(time.Second))
+		c.ru.SetReadDeadline(time.Now().Add(time.Second))
 
 		n, err := c.ru.Read(b)
 
@@ -81,9 +231,7 @@ func (c *Channel) Scan(ch chan<- []byte, state []byte) {
 		} else if err != nil {
 			sys.Fatal(err)
 		} else {
-			ch <- b[:n]
+			fn(b[:n])
 		}
 	}
-
-	close(ch)
 }
@@ -30,6 +30,20 @@ func TestNewChannel(t *testing.T) {
 	})
 }
 
+func TestClose(t *testing.T) {
+	t.Run("Close should close the connections", func(t *testing.T) {
+		c := NewChannel(_host)
+
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.Close(); err == nil {
+			t.Fatal("Connections were not closed")
+		}
+	})
+}
+
 func TestSend(t *testing.T) {
 	if os.Getenv("CI") != "" {
 		t.Skip() // Faulty CI
@@ -0,0 +1,61 @@
+package subspace
+
+import (
+	"fmt"
+
+	"github.com/cuhsat/subspace/internal/pkg/wire"
+	"github.com/cuhsat/subspace/pkg/sub"
+)
+
+// Admin executes the admin operation of the given frame on the given
+// topic and returns the response datagrams. States will be listed and
+// inspected as their name followed by their lag. Altering operations
+// will respond with ok, if the state existed. Acknowledgements will
+// not be responded, as they are sent without waiting.
+//
+// Unknown operations will be ignored, same as copies and renames
+// without a target.
+func admin(t *sub.Space, f *wire.Frame) (l [][]byte) {
+	ok := false
+
+	// copies and renames need a target
+	if (string(f.Op) == wire.OpCopy || string(f.Op) == wire.OpRename) && len(f.Target) == 0 {
+		return
+	}
+
+	switch string(f.Op) {
+	case wire.OpList:
+		for _, k := range t.States() {
+			n, _ := t.Lag([]byte(k))
+
+			l = append(l, fmt.Appendf(nil, "%s %d", k, n))
+		}
+
+		return
+
+	case wire.OpInspect:
+		if n, ok := t.Lag(f.State); ok {
+			l = append(l, fmt.Appendf(nil, "%s %d", f.State, n))
+		}
+
+		return
+
+	case wire.OpDelete:
+		ok = t.Delete(f.State)
+
+	case wire.OpCopy:
+		ok = t.Copy(f.State, f.Target)
+
+	case wire.OpRename:
+		ok = t.Rename(f.State, f.Target)
+
+	case wire.OpAck:
+		t.Ack(f.State, f.Offset)
+	}
+
+	if ok {
+		l = append(l, []byte("ok"))
+	}
+
+	return
+}
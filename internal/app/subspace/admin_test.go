@@ -0,0 +1,91 @@
+package subspace
+
+import (
+	"testing"
+
+	"github.com/cuhsat/subspace/internal/pkg/wire"
+)
+
+func TestAdmin(t *testing.T) {
+	t.Run("Admin should list states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+		s.Scan(make(chan []byte, 1), _foo)
+		s.Send(_bar)
+
+		l := admin(s, &wire.Frame{Op: []byte(wire.OpList)})
+
+		if len(l) != 1 || string(l[0]) != "foo 1" {
+			t.Fatal("States are not correct")
+		}
+	})
+
+	t.Run("Admin should rename states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+		s.Scan(make(chan []byte, 1), _foo)
+
+		l := admin(s, &wire.Frame{Op: []byte(wire.OpRename), State: _foo, Target: _bar})
+
+		if len(l) != 1 || string(l[0]) != "ok" {
+			t.Fatal("Response is not correct")
+		}
+
+		if _, ok := s.Lag(_bar); !ok {
+			t.Fatal("State was not renamed")
+		}
+	})
+
+	t.Run("Admin should refuse renames without a target", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+		s.Scan(make(chan []byte, 1), _foo)
+
+		l := admin(s, &wire.Frame{Op: []byte(wire.OpRename), State: _foo, Target: []byte{}})
+
+		if len(l) != 0 {
+			t.Fatal("Response is not empty")
+		}
+
+		if _, ok := s.Lag(_foo); !ok {
+			t.Fatal("State was renamed")
+		}
+	})
+
+	t.Run("Admin should acknowledge states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+
+		l := admin(s, &wire.Frame{Op: []byte(wire.OpAck), State: _foo, Offset: 1})
+
+		if len(l) != 0 {
+			t.Fatal("Response is not empty")
+		}
+
+		if n, ok := s.Lag(_foo); !ok || n != 0 {
+			t.Fatal("State was not acknowledged")
+		}
+	})
+
+	t.Run("Admin should ignore unknown states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		l := admin(_s.Load(), &wire.Frame{Op: []byte(wire.OpDelete), State: _foo})
+
+		if len(l) != 0 {
+			t.Fatal("Response is not empty")
+		}
+	})
+}
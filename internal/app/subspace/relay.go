@@ -2,11 +2,15 @@
 package subspace
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"net"
 	"runtime"
 	"sync/atomic"
 
 	"github.com/cuhsat/subspace/internal/pkg/sys"
+	"github.com/cuhsat/subspace/internal/pkg/wire"
 	"github.com/cuhsat/subspace/pkg/sub"
 )
 
@@ -20,6 +24,8 @@ var (
 	Tx uint64
 	// Forwarded bytes.
 	Fx uint64
+	// Rejected bytes.
+	Rj uint64
 	// Data channel.
 	dc atomic.Pointer[chan []byte]
 )
@@ -49,6 +55,7 @@ func NewRelay(host string) (r *relay) {
 }
 
 // Relay forwards all received signal data the to given relays.
+// Queued unframed signal data will be packed into relay batch frames.
 //
 // Relay will count all transmitted bytes.
 //
@@ -60,36 +67,75 @@ func Relay(hosts []string) {
 		rs = append(rs, NewRelay(host))
 	}
 
-	ch := make(chan []byte)
+	ch := make(chan []byte, 64)
 
 	dc.Store(&ch)
 
 	go func() {
-		for b := range *dc.Load() {
-			for _, r := range rs {
-				n, err := r.tu.Write(b)
+		for b := range ch {
+			for _, v := range pack(b, ch) {
+				for _, r := range rs {
+					n, err := r.tu.Write(v)
 
-				if err != nil {
-					sys.Fatal(err)
-				}
+					if err != nil {
+						sys.Fatal(err)
+					}
 
-				atomic.AddUint64(&Fx, uint64(n))
+					atomic.AddUint64(&Fx, uint64(n))
+				}
 			}
 		}
 	}()
 }
 
+// Frame of batches packed by the relay.
+var relayed = &wire.Frame{Relay: true}
+
+// Pack packs the given and all queued unframed signal data into
+// relay batch frames, keeping their order. Framed data is kept as is.
+func pack(b []byte, ch <-chan []byte) (bs [][]byte) {
+	var l [][]byte
+
+	for i := 0; ; i++ {
+		if bytes.HasPrefix(b, []byte(wire.Magic)) {
+			bs, l = append(append(bs, relayed.Pack(l, sys.MaxBuffer)...), b), nil
+		} else {
+			l = append(l, b)
+		}
+
+		if i == cap(ch) {
+			break
+		}
+
+		select {
+		case b = <-ch:
+			continue
+		default:
+		}
+
+		break
+	}
+
+	return append(bs, relayed.Pack(l, sys.MaxBuffer)...)
+}
+
 // Send receives data from an UDP pseudo connection
 // and send this data as a signal to the given subspace.
+// If the data is framed, the signal is send to the frames topic
+// with the frames time to live, header, key and producer id. All signals of
+// a framed batch will be sent at once. As relay batches are packed
+// from signals of different senders, a relay batch rejected
+// by the limits of the subspace will be sent signal by signal.
+// Frames that can not be decoded will be dropped.
 //
-// Send will count all received bytes.
+// Send will count all received and rejected bytes.
 func Send(u *net.UDPConn, s *sub.Space) {
 	b := sys.NewBuffer()
 
 	n, _, err := u.ReadFromUDP(b)
 
-	if err == nil {
-		go s.Send(b[:n])
+	if f := wire.Signal(b[:n]); err == nil && f != nil {
+		go accept(s, f)
 	}
 
 	atomic.AddUint64(&Rx, uint64(n))
@@ -99,10 +145,51 @@ func Send(u *net.UDPConn, s *sub.Space) {
 	}
 }
 
+// Accept sends the signals of the given frame to the given subspace
+// and counts all rejected bytes.
+func accept(s *sub.Space, f *wire.Frame) {
+	t, attrs := s.Topic(string(f.Topic)), []sub.Attr{sub.TTL(f.TTL), sub.Headers(f.Header), sub.Key(string(f.Key))}
+
+	if f.Batch == nil {
+		if _, err := t.TrySend(f.Data, append(attrs, sub.ID(string(f.ID)))...); err != nil {
+			atomic.AddUint64(&Rj, uint64(len(f.Data)))
+		}
+
+		return
+	}
+
+	_, berr := t.SendBatch(f.Batch, attrs...)
+
+	for _, d := range f.Batch {
+		err := berr
+
+		// retry signal by signal, if the relay batch did not fit
+		if f.Relay && errors.Is(berr, sub.ErrFull) {
+			_, err = t.TrySend(d, attrs...)
+		}
+
+		if err != nil {
+			atomic.AddUint64(&Rj, uint64(len(d)))
+		}
+	}
+}
+
 // Scan receives a state id from an UDP pseudo connection
 // and scans the given subspace using the id for new signals.
+// If the state id is framed, the frames topic will be scanned
+// and the state will be moved to the frames time first, if given.
+// Framed admin operations on states will be answered instead of a scan.
+// Requests for unknown topics will be ignored, as these topics are empty,
+// same as frames that can not be decoded.
+// Only signals selected by the frames filter terms will be scanned,
+// requests with invalid filter terms will be ignored. Paged scans
+// will stop at the frames limits and respond with framed offsets,
+// same as leased scans, which only advance the state on an ack.
 //
-// Scanned signals are send in parallel to the received address.
+// Scanned signals are send in parallel to the received address,
+// framed if they have a header.
+// The scan will be aborted, if the signals could not be send
+// before the scan timeout is reached or if sending fails.
 //
 // Scan will count all received and transmitted bytes.
 func Scan(u *net.UDPConn, s *sub.Space) {
@@ -112,17 +199,77 @@ func Scan(u *net.UDPConn, s *sub.Space) {
 
 	atomic.AddUint64(&Rx, uint64(n))
 
-	if err == nil {
-		ch := make(chan []byte)
+	if f := wire.Request(b[:n]); err == nil && f != nil {
+		// unknown topics are empty and will not be created
+		t, ok := s.LookupTopic(string(f.Topic))
 
-		go s.Scan(ch, b[:n])
+		if !ok {
+			return
+		}
 
-		go func() {
-			for v := range ch {
+		// respond to admin operations instead
+		if f.Op != nil {
+			for _, v := range admin(t, f) {
 				n, _ := u.WriteToUDP(v, addr)
 
 				atomic.AddUint64(&Tx, uint64(n))
 			}
+
+			return
+		}
+
+		var fs []sub.Filter
+
+		for _, v := range f.Filter {
+			fl, err := sub.ParseFilter(string(v))
+
+			if err != nil {
+				return // invalid filter
+			}
+
+			fs = append(fs, fl)
+		}
+
+		if f.Since != 0 {
+			t.Seek(f.State, f.Since)
+		}
+
+		if f.Offset != 0 {
+			t.SeekOffset(f.State, f.Offset)
+		}
+
+		paged := f.Count > 0 || f.Size > 0 || f.Lease != 0
+
+		ch := make(chan sub.Message)
+
+		ctx, cancel := context.WithTimeout(context.Background(), sys.Timeout)
+
+		if f.Lease != 0 {
+			go t.Lease(ctx, ch, f.State, int(f.Count), int(f.Size), f.Lease, fs...)
+		} else {
+			go t.ScanPage(ctx, ch, f.State, int(f.Count), int(f.Size), fs...)
+		}
+
+		go func() {
+			defer cancel()
+
+			for m := range ch {
+				r := wire.Frame{Data: m.Data, Header: m.Header}
+
+				if paged {
+					r.Offset = m.Offset
+				}
+
+				v := r.Encode()
+
+				n, err := u.WriteToUDP(v, addr)
+
+				if err != nil {
+					cancel()
+				}
+
+				atomic.AddUint64(&Tx, uint64(n))
+			}
 		}()
 	}
 }
@@ -1,12 +1,14 @@
 package subspace
 
 import (
+	"bytes"
 	"os"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/cuhsat/subspace/internal/pkg/sys"
+	"github.com/cuhsat/subspace/internal/pkg/wire"
 	"github.com/cuhsat/subspace/pkg/sub"
 )
 
@@ -31,25 +33,110 @@ func TestRelay(t *testing.T) {
 	t.Run("Relay should relay a signal to a relay", func(t *testing.T) {
 		t.Cleanup(_cleanup)
 
-		go Relay([]string{"localhost"})
+		Relay([]string{"localhost"})
 
 		s := _s.Load()
 		u := sys.Listen("localhost" + sys.Port1)
 
 		defer u.Close()
 
-		go Send(u, s)
+		fx := atomic.LoadUint64(&Fx)
 
 		_sendOnce()
 
-		time.Sleep(time.Millisecond)
+		Send(u, s)
 
-		if atomic.LoadUint64(&Fx) == 0 {
+		if !_await(func() bool { return atomic.LoadUint64(&Fx) > fx }) {
 			t.Fatal("Signal was not relayed")
 		}
 	})
 }
 
+func TestPack(t *testing.T) {
+	t.Run("Pack should pack queued signals into a batch", func(t *testing.T) {
+		ch := make(chan []byte, 2)
+
+		ch <- _bar
+		ch <- _foo
+
+		bs := pack(_foo, ch)
+
+		if len(bs) != 1 || len(ch) != 0 {
+			t.Fatal("Batch count is not correct")
+		}
+
+		if f := wire.Signal(bs[0]); len(f.Batch) != 3 || !bytes.Equal(f.Batch[1], _bar) || !f.Relay {
+			t.Fatal("Batch is not correct")
+		}
+	})
+
+	t.Run("Pack should keep framed signals as they are", func(t *testing.T) {
+		ch := make(chan []byte, 2)
+
+		b := (&wire.Frame{Topic: _bar, Data: _foo}).Encode()
+
+		ch <- b
+		ch <- _bar
+
+		bs := pack(_foo, ch)
+
+		if len(bs) != 3 || !bytes.Equal(bs[0], _foo) || !bytes.Equal(bs[1], b) || !bytes.Equal(bs[2], _bar) {
+			t.Fatal("Signals are not correct")
+		}
+	})
+}
+
+func TestAccept(t *testing.T) {
+	t.Run("Accept should send a relay batch signal by signal, if full", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(2, 0, sub.Evict)
+
+		rj := atomic.LoadUint64(&Rj)
+
+		accept(s, &wire.Frame{Batch: [][]byte{_foo, _bar, _foo}, Relay: true})
+
+		if s.StatCount != 2 || atomic.LoadUint64(&Rj) != rj {
+			t.Fatal("Batch was not sent")
+		}
+	})
+
+	t.Run("Accept should count rejected signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, sub.Reject)
+
+		rj := atomic.LoadUint64(&Rj)
+
+		accept(s, &wire.Frame{Batch: [][]byte{_foo, _bar}, Relay: true})
+
+		if s.StatCount != 1 || atomic.LoadUint64(&Rj) != rj+3 {
+			t.Fatal("Rejected bytes are not correct")
+		}
+	})
+
+	t.Run("Accept should send other batches at once", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(2, 0, sub.Reject)
+		s.Send(_foo)
+
+		rj := atomic.LoadUint64(&Rj)
+
+		accept(s, &wire.Frame{Batch: [][]byte{_foo, _bar}})
+
+		if s.StatCount != 1 || atomic.LoadUint64(&Rj) != rj+6 {
+			t.Fatal("Batch was sent partly")
+		}
+	})
+}
+
 func TestSend(t *testing.T) {
 	if os.Getenv("CI") != "" {
 		t.Skip() // Faulty CI
@@ -63,13 +150,11 @@ func TestSend(t *testing.T) {
 
 		defer u.Close()
 
-		go Send(u, s)
-
 		_sendOnce()
 
-		time.Sleep(time.Millisecond)
+		Send(u, s)
 
-		if atomic.LoadUint64(&s.StatCount) == 0 {
+		if !_await(func() bool { return atomic.LoadUint64(&s.StatCount) > 0 }) {
 			t.Fatal("Signal was not send")
 		}
 	})
@@ -214,6 +299,18 @@ func _scanLoop(loop *bool) {
 	u.Close()
 }
 
+func _await(fn func() bool) bool {
+	for d := time.Now().Add(time.Second); time.Now().Before(d); time.Sleep(time.Millisecond) {
+		if fn() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func _cleanup() {
-	_s.Swap(sub.NewSpace())
+	if s := _s.Swap(sub.NewSpace()); s != nil {
+		s.Close()
+	}
 }
@@ -0,0 +1,32 @@
+package subspace
+
+import (
+	"sync/atomic"
+
+	"github.com/cuhsat/subspace/pkg/sub"
+)
+
+// A Report holds the stats of a subspace server.
+type Report struct {
+	sub.Stats
+	// Received bytes.
+	Rx uint64
+	// Transmitted bytes.
+	Tx uint64
+	// Forwarded bytes.
+	Fx uint64
+	// Rejected bytes.
+	Rj uint64
+}
+
+// Stats returns a report of the total stats of all topics
+// of the given space and the traffic of the server.
+func Stats(s *sub.Space) Report {
+	return Report{
+		Stats: s.TotalStats(),
+		Rx:    atomic.LoadUint64(&Rx),
+		Tx:    atomic.LoadUint64(&Tx),
+		Fx:    atomic.LoadUint64(&Fx),
+		Rj:    atomic.LoadUint64(&Rj),
+	}
+}
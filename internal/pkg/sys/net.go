@@ -2,6 +2,7 @@ package sys
 
 import (
 	"net"
+	"time"
 )
 
 // MaxBuffer is the maximum allowed buffer size
@@ -14,6 +15,10 @@ const (
 	Port2 = ":8212" // outgoing signal port address.
 )
 
+// Timeout is the maximum duration of a single scan,
+// after which the scan will be aborted.
+const Timeout = 10 * time.Second
+
 // NewBuffer returns a signal buffer ready to use.
 func NewBuffer() []byte {
 	return make([]byte, MaxBuffer)
@@ -0,0 +1,323 @@
+// Package wire implements the subspace frame format.
+//
+// A frame is a datagram starting with the Magic bytes, followed by any number
+// of fields. Each field consists of a tag byte, the length of its value as an
+// unsigned varint and the value itself. Unknown fields will be skipped.
+//
+// The header field may occur multiple times, once for every key value pair.
+// Its value consists of the length of the key as an unsigned varint, the key
+// and the value. The filter field may occur multiple times, once for every
+// filter term. The batch field may occur multiple times, once for every
+// signal of a batch, which will be sent at once. The empty relay field marks
+// a batch packed by a relay from the signals of different senders.
+//
+// Datagrams without the Magic bytes are raw and carry either a plain signal
+// (on the incoming port and as scan response) or a plain state id (on the
+// outgoing port). Only scanned signals with a header will be framed.
+// Datagrams with the Magic bytes, that can not be decoded, will be dropped.
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"maps"
+	"slices"
+)
+
+// Magic marks the beginning of a frame. The byte 0xFE will never occur
+// in UTF-8 encoded text, so raw text signals can not be mistaken as frames.
+const Magic = "\xfe\x53"
+
+// Field tags.
+const (
+	tagTopic byte = iota + 1
+	tagState
+	tagData
+	tagTTL
+	tagSince
+	tagOp
+	tagTarget
+	tagHeader
+	tagFilter
+	tagCount
+	tagSize
+	tagOffset
+	tagLease
+	tagKey
+	tagBatch
+	tagID
+	tagRelay
+)
+
+// Admin operations on states.
+const (
+	OpList    = "list"    // list all states with their lag.
+	OpInspect = "inspect" // show the lag of a state.
+	OpDelete  = "delete"  // delete a state.
+	OpCopy    = "copy"    // copy a state to the target.
+	OpRename  = "rename"  // rename a state to the target.
+	OpAck     = "ack"     // acknowledge a state up to the offset.
+)
+
+// A frame is a single request or response datagram.
+type Frame struct {
+	Topic  []byte            // topic name.
+	State  []byte            // state id.
+	Data   []byte            // signal data.
+	TTL    int64             // time to live in milliseconds, 0 for the default.
+	Since  int64             // scan start in milliseconds since epoch, 0 for the state.
+	Op     []byte            // admin operation, nil for a scan.
+	Target []byte            // target state id of an admin operation.
+	Header map[string]string // signal header, one field per pair.
+	Filter [][]byte          // scan filter terms, one field per term.
+	Count  uint64            // maximum count of scanned signals, 0 for no limit.
+	Size   uint64            // maximum size of scanned signals, 0 for no limit.
+	Offset uint64            // scan start (request) or signal offset (response).
+	Lease  int64             // lease time of scanned signals in milliseconds, 0 for no lease.
+	Key    []byte            // signal key for compaction.
+	Batch  [][]byte          // signal data of a batch, one field per signal.
+	ID     []byte            // producer id of a single signal for deduplication.
+	Relay  bool              // batch packed by a relay from different senders.
+}
+
+// Signal decodes a datagram received on the incoming signal port.
+// Raw datagrams will be returned as the frames data. Datagrams starting
+// with the Magic bytes, that can not be decoded, e.g. because they were
+// truncated, will be returned as nil.
+func Signal(b []byte) *Frame {
+	if f, ok := Decode(b); ok {
+		return f
+	}
+
+	if !raw(b) {
+		return nil
+	}
+
+	return &Frame{Data: b}
+}
+
+// Request decodes a datagram received on the outgoing signal port.
+// Raw datagrams will be returned as the frames state. Same as for
+// raw datagrams, a missing state will be returned as an empty state.
+// Datagrams that can not be decoded will be returned as nil, same as
+// by Signal.
+func Request(b []byte) *Frame {
+	if f, ok := Decode(b); ok {
+		if f.State == nil {
+			f.State = []byte{}
+		}
+
+		return f
+	}
+
+	if !raw(b) {
+		return nil
+	}
+
+	return &Frame{State: b}
+}
+
+// Decode parses the given datagram as a frame. It reports whether
+// the datagram was a valid frame.
+func Decode(b []byte) (f *Frame, ok bool) {
+	if !bytes.HasPrefix(b, []byte(Magic)) {
+		return
+	}
+
+	f, b = &Frame{}, b[len(Magic):]
+
+	for len(b) > 0 {
+		t := b[0]
+
+		n, l := binary.Uvarint(b[1:])
+
+		if l <= 0 || n > uint64(len(b)-1-l) {
+			return nil, false
+		}
+
+		v := b[1+l : 1+l+int(n)]
+
+		switch t {
+		case tagTopic:
+			f.Topic = v
+		case tagState:
+			f.State = v
+		case tagData:
+			f.Data = v
+		case tagTTL:
+			f.TTL, _ = binary.Varint(v)
+		case tagSince:
+			f.Since, _ = binary.Varint(v)
+		case tagOp:
+			f.Op = v
+		case tagTarget:
+			f.Target = v
+		case tagHeader:
+			k, l := binary.Uvarint(v)
+
+			if l <= 0 || k > uint64(len(v)-l) {
+				return nil, false
+			}
+
+			if f.Header == nil {
+				f.Header = make(map[string]string)
+			}
+
+			f.Header[string(v[l:l+int(k)])] = string(v[l+int(k):])
+		case tagFilter:
+			f.Filter = append(f.Filter, v)
+		case tagCount:
+			f.Count, _ = binary.Uvarint(v)
+		case tagSize:
+			f.Size, _ = binary.Uvarint(v)
+		case tagOffset:
+			f.Offset, _ = binary.Uvarint(v)
+		case tagLease:
+			f.Lease, _ = binary.Varint(v)
+		case tagKey:
+			f.Key = v
+		case tagBatch:
+			f.Batch = append(f.Batch, v)
+		case tagID:
+			f.ID = v
+		case tagRelay:
+			f.Relay = true
+		}
+
+		b = b[1+l+int(n):]
+	}
+
+	return f, true
+}
+
+// Encode returns the frame as a datagram. A frame with either only
+// data or only a state will be returned raw, to stay compatible with
+// older subspace servers and clients.
+func (f *Frame) Encode() []byte {
+	if f.bare() {
+		if f.State == nil && raw(f.Data) {
+			return f.Data
+		}
+
+		if f.Data == nil && raw(f.State) {
+			return f.State
+		}
+	}
+
+	b := []byte(Magic)
+
+	b = field(b, tagTopic, f.Topic)
+	b = field(b, tagState, f.State)
+	b = field(b, tagData, f.Data)
+	b = field(b, tagOp, f.Op)
+	b = field(b, tagTarget, f.Target)
+	b = field(b, tagKey, f.Key)
+	b = field(b, tagID, f.ID)
+
+	for _, k := range slices.Sorted(maps.Keys(f.Header)) {
+		v := binary.AppendUvarint(nil, uint64(len(k)))
+
+		b = field(b, tagHeader, append(append(v, k...), f.Header[k]...))
+	}
+
+	for _, v := range f.Filter {
+		b = field(b, tagFilter, v)
+	}
+
+	for _, v := range f.Batch {
+		b = field(b, tagBatch, v)
+	}
+
+	if f.Relay {
+		b = field(b, tagRelay, []byte{})
+	}
+
+	if f.Count != 0 {
+		b = field(b, tagCount, binary.AppendUvarint(nil, f.Count))
+	}
+
+	if f.Size != 0 {
+		b = field(b, tagSize, binary.AppendUvarint(nil, f.Size))
+	}
+
+	if f.Offset != 0 {
+		b = field(b, tagOffset, binary.AppendUvarint(nil, f.Offset))
+	}
+
+	if f.Lease != 0 {
+		b = field(b, tagLease, binary.AppendVarint(nil, f.Lease))
+	}
+
+	if f.TTL != 0 {
+		b = field(b, tagTTL, binary.AppendVarint(nil, f.TTL))
+	}
+
+	if f.Since != 0 {
+		b = field(b, tagSince, binary.AppendVarint(nil, f.Since))
+	}
+
+	return b
+}
+
+// Pack returns the given signals as datagrams of the frame, packing as
+// many signals as possible into a batch, without exceeding the given size.
+// A signal that does not fit into a batch with others will be sent alone
+// as the frames data, even if the datagram exceeds the given size, so
+// that the caller has to check the size of every returned datagram.
+// The producer id of the frame will not be packed and
+// the relay mark will only be kept for batches.
+func (f *Frame) Pack(l [][]byte, size int) (bs [][]byte) {
+	b := *f
+
+	b.Data, b.Batch, b.ID = nil, [][]byte{}, nil
+
+	base := len(b.Encode())
+
+	for n := base; len(l) > 0; n = base {
+		i := 0
+
+		for ; i < len(l); i++ {
+			if n += 1 + uvarintLen(len(l[i])) + len(l[i]); n > size && i > 0 {
+				break
+			}
+		}
+
+		if i == 1 {
+			b.Data, b.Batch, b.Relay = l[0], nil, false
+		} else {
+			b.Data, b.Batch, b.Relay = nil, l[:i], f.Relay
+		}
+
+		bs, l = append(bs, b.Encode()), l[i:]
+	}
+
+	return
+}
+
+// Bare reports whether the frame has no other fields than data and state.
+func (f *Frame) bare() bool {
+	return f.Topic == nil && f.Op == nil && f.Target == nil && f.Key == nil && f.ID == nil && len(f.Header) == 0 && f.Filter == nil &&
+		f.Batch == nil && !f.Relay && f.TTL == 0 && f.Since == 0 && f.Count == 0 && f.Size == 0 && f.Offset == 0 && f.Lease == 0
+}
+
+// Raw reports whether the given value can be send without a frame.
+func raw(v []byte) bool {
+	return !bytes.HasPrefix(v, []byte(Magic))
+}
+
+// UvarintLen returns the length of the given value as an unsigned varint.
+func uvarintLen(v int) int {
+	return len(binary.AppendUvarint(nil, uint64(v)))
+}
+
+// Field appends the given value as a tagged field. Nil values are omitted.
+func field(b []byte, t byte, v []byte) []byte {
+	if v == nil {
+		return b
+	}
+
+	b = append(b, t)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+
+	return append(b, v...)
+}
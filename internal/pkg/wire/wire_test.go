@@ -0,0 +1,302 @@
+package wire
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	_foo = []byte("foo")
+	_bar = []byte("bar")
+)
+
+func TestSignal(t *testing.T) {
+	t.Run("Signal should return raw data", func(t *testing.T) {
+		f := Signal(_foo)
+
+		if !bytes.Equal(f.Data, _foo) {
+			t.Fatal("Data is not correct")
+		}
+
+		if f.Topic != nil {
+			t.Fatal("Topic is not nil")
+		}
+	})
+
+	t.Run("Signal should return framed data", func(t *testing.T) {
+		f := Signal((&Frame{Topic: _bar, Data: _foo}).Encode())
+
+		if !bytes.Equal(f.Data, _foo) {
+			t.Fatal("Data is not correct")
+		}
+
+		if !bytes.Equal(f.Topic, _bar) {
+			t.Fatal("Topic is not correct")
+		}
+	})
+
+	t.Run("Signal should drop truncated frames", func(t *testing.T) {
+		b := (&Frame{Topic: _bar, Data: _foo}).Encode()
+
+		if f := Signal(b[:len(b)-1]); f != nil {
+			t.Fatal("Frame was not dropped")
+		}
+	})
+}
+
+func TestTTL(t *testing.T) {
+	t.Run("TTL should be framed", func(t *testing.T) {
+		f := Signal((&Frame{Data: _foo, TTL: 1000}).Encode())
+
+		if f.TTL != 1000 {
+			t.Fatal("TTL is not correct")
+		}
+
+		if !bytes.Equal(f.Data, _foo) {
+			t.Fatal("Data is not correct")
+		}
+	})
+}
+
+func TestSince(t *testing.T) {
+	t.Run("Since should be framed", func(t *testing.T) {
+		f := Request((&Frame{State: _foo, Since: 1000}).Encode())
+
+		if f.Since != 1000 {
+			t.Fatal("Since is not correct")
+		}
+
+		if !bytes.Equal(f.State, _foo) {
+			t.Fatal("State is not correct")
+		}
+	})
+}
+
+func TestKey(t *testing.T) {
+	t.Run("Key should be framed", func(t *testing.T) {
+		f := Signal((&Frame{Data: _foo, Key: _bar}).Encode())
+
+		if !bytes.Equal(f.Key, _bar) {
+			t.Fatal("Key is not correct")
+		}
+	})
+}
+
+func TestID(t *testing.T) {
+	t.Run("ID should be framed", func(t *testing.T) {
+		f := Signal((&Frame{Data: _foo, ID: _bar}).Encode())
+
+		if !bytes.Equal(f.ID, _bar) {
+			t.Fatal("ID is not correct")
+		}
+	})
+
+	t.Run("ID should not be packed", func(t *testing.T) {
+		bs := (&Frame{ID: _bar}).Pack([][]byte{_foo, _foo}, 1024)
+
+		if f := Signal(bs[0]); f.ID != nil || len(f.Batch) != 2 {
+			t.Fatal("ID is not correct")
+		}
+	})
+}
+
+func TestHeader(t *testing.T) {
+	t.Run("Header should be framed", func(t *testing.T) {
+		f := Signal((&Frame{Data: _foo, Header: map[string]string{"a": "1", "bb": ""}}).Encode())
+
+		if len(f.Header) != 2 || f.Header["a"] != "1" || f.Header["bb"] != "" {
+			t.Fatal("Header is not correct")
+		}
+
+		if !bytes.Equal(f.Data, _foo) {
+			t.Fatal("Data is not correct")
+		}
+	})
+}
+
+func TestFilter(t *testing.T) {
+	t.Run("Filter should be framed", func(t *testing.T) {
+		f := Request((&Frame{State: _foo, Filter: [][]byte{_foo, _bar}}).Encode())
+
+		if len(f.Filter) != 2 || !bytes.Equal(f.Filter[1], _bar) {
+			t.Fatal("Filter is not correct")
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("Batch should be framed", func(t *testing.T) {
+		f := Signal((&Frame{Topic: _foo, Batch: [][]byte{_foo, _bar}}).Encode())
+
+		if len(f.Batch) != 2 || !bytes.Equal(f.Batch[1], _bar) || !bytes.Equal(f.Topic, _foo) {
+			t.Fatal("Batch is not correct")
+		}
+	})
+
+	t.Run("Pack should pack signals into batches", func(t *testing.T) {
+		l := (&Frame{Topic: _foo}).Pack([][]byte{_foo, _bar, _foo, _bar}, 20)
+
+		if len(l) != 2 {
+			t.Fatal("Count is not correct")
+		}
+
+		for _, b := range l {
+			if f := Signal(b); len(b) > 20 || len(f.Batch) != 2 || !bytes.Equal(f.Topic, _foo) {
+				t.Fatal("Batch is not correct")
+			}
+		}
+	})
+
+	t.Run("Pack should send single signals raw", func(t *testing.T) {
+		l := (&Frame{}).Pack([][]byte{_foo}, 20)
+
+		if len(l) != 1 || !bytes.Equal(l[0], _foo) {
+			t.Fatal("Signal is not correct")
+		}
+	})
+
+	t.Run("Pack should mark only batches as relayed", func(t *testing.T) {
+		l := (&Frame{Relay: true}).Pack([][]byte{_foo, _bar, []byte("0123456789")}, 20)
+
+		if len(l) != 2 || !Signal(l[0]).Relay || !bytes.Equal(l[1], []byte("0123456789")) {
+			t.Fatal("Relay is not correct")
+		}
+	})
+}
+
+func TestPage(t *testing.T) {
+	t.Run("Page should be framed", func(t *testing.T) {
+		f := Request((&Frame{State: _foo, Count: 1, Size: 2, Offset: 3}).Encode())
+
+		if f.Count != 1 || f.Size != 2 || f.Offset != 3 {
+			t.Fatal("Page is not correct")
+		}
+	})
+
+	t.Run("Lease should be framed", func(t *testing.T) {
+		f := Request((&Frame{State: _foo, Lease: 1000}).Encode())
+
+		if f.Lease != 1000 {
+			t.Fatal("Lease is not correct")
+		}
+	})
+}
+
+func TestOp(t *testing.T) {
+	t.Run("Op should be framed", func(t *testing.T) {
+		f := Request((&Frame{State: _foo, Op: []byte(OpCopy), Target: _bar}).Encode())
+
+		if string(f.Op) != OpCopy {
+			t.Fatal("Op is not correct")
+		}
+
+		if !bytes.Equal(f.Target, _bar) {
+			t.Fatal("Target is not correct")
+		}
+	})
+}
+
+func TestRequest(t *testing.T) {
+	t.Run("Request should return raw state", func(t *testing.T) {
+		f := Request(_foo)
+
+		if !bytes.Equal(f.State, _foo) {
+			t.Fatal("State is not correct")
+		}
+	})
+
+	t.Run("Request should return framed state", func(t *testing.T) {
+		f := Request((&Frame{Topic: _bar, State: _foo}).Encode())
+
+		if !bytes.Equal(f.State, _foo) {
+			t.Fatal("State is not correct")
+		}
+
+		if !bytes.Equal(f.Topic, _bar) {
+			t.Fatal("Topic is not correct")
+		}
+	})
+
+	t.Run("Request should drop truncated frames", func(t *testing.T) {
+		b := (&Frame{Topic: _bar, State: _foo}).Encode()
+
+		if f := Request(b[:len(b)-1]); f != nil {
+			t.Fatal("Frame was not dropped")
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Decode should fail for raw data", func(t *testing.T) {
+		if _, ok := Decode(_foo); ok {
+			t.Fatal("Raw data was decoded")
+		}
+	})
+
+	t.Run("Decode should fail for truncated frames", func(t *testing.T) {
+		b := (&Frame{Topic: _bar, Data: _foo}).Encode()
+
+		if _, ok := Decode(b[:len(b)-1]); ok {
+			t.Fatal("Truncated frame was decoded")
+		}
+	})
+
+	t.Run("Decode should skip unknown fields", func(t *testing.T) {
+		b := append([]byte(Magic), 0xff, 1, 0)
+		b = field(b, tagData, _foo)
+
+		f, ok := Decode(b)
+
+		if !ok {
+			t.Fatal("Frame was not decoded")
+		}
+
+		if !bytes.Equal(f.Data, _foo) {
+			t.Fatal("Data is not correct")
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("Encode should return raw data", func(t *testing.T) {
+		if !bytes.Equal((&Frame{Data: _foo}).Encode(), _foo) {
+			t.Fatal("Data is not raw")
+		}
+	})
+
+	t.Run("Encode should return raw state", func(t *testing.T) {
+		if !bytes.Equal((&Frame{State: _foo}).Encode(), _foo) {
+			t.Fatal("State is not raw")
+		}
+	})
+
+	t.Run("Encode should frame data looking like a frame", func(t *testing.T) {
+		d := []byte(Magic + "foo")
+
+		f, ok := Decode((&Frame{Data: d}).Encode())
+
+		if !ok || !bytes.Equal(f.Data, d) {
+			t.Fatal("Data is not framed")
+		}
+	})
+}
+
+func BenchmarkEncode(b *testing.B) {
+	b.Run("Benchmark Encode", func(b *testing.B) {
+		f := &Frame{Topic: _bar, Data: _foo}
+
+		for n := 0; n < b.N; n++ {
+			f.Encode()
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.Run("Benchmark Decode", func(b *testing.B) {
+		d := (&Frame{Topic: _bar, Data: _foo}).Encode()
+
+		for n := 0; n < b.N; n++ {
+			Decode(d)
+		}
+	})
+}
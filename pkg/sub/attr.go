@@ -0,0 +1,37 @@
+package sub
+
+import (
+	"maps"
+)
+
+// An Attr sets an additional attribute of a signal on sending.
+type Attr func(x *signal)
+
+// TTL sets the time to live of a signal in milliseconds. The signal will
+// be dropped as soon as it is older than its time to live, regardless of
+// the retention time given to Drop. A time to live of zero stands for the
+// retention time given to Drop.
+func TTL(ttl int64) Attr {
+	return func(x *signal) {
+		x.ttl = ttl
+	}
+}
+
+// Headers sets a header of key value pairs of a signal, like its content
+// type, origin or trace id. The header will be delivered along with the
+// signal data by ScanMessages.
+func Headers(h map[string]string) Attr {
+	return func(x *signal) {
+		x.header = maps.Clone(h)
+	}
+}
+
+// Expired reports whether the signal is older than its time to live
+// or older than the given retention time, if it has none.
+func (x *signal) expired(now, retention int64) bool {
+	if x.ttl != 0 {
+		retention = x.ttl
+	}
+
+	return now-x.time > retention
+}
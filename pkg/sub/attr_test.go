@@ -0,0 +1,197 @@
+package sub
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTTL(t *testing.T) {
+	t.Run("TTL should set the time to live", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo, TTL(1))
+
+		if s.head.ttl != 1 {
+			t.Fatal("Time to live is not correct")
+		}
+
+		if s.ttls != 1 {
+			t.Fatal("Count is not correct")
+		}
+	})
+
+	t.Run("TTL should drop before the retention time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo, TTL(1))
+
+		_tick(10)
+
+		s.Drop(Infinite)
+
+		if s.head != s.root {
+			t.Fatal("Signal was not dropped")
+		}
+
+		if s.ttls != 0 {
+			t.Fatal("Count is not correct")
+		}
+	})
+
+	t.Run("TTL should keep after the retention time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo, TTL(Infinite))
+
+		_drop()
+
+		if s.head == s.root {
+			t.Fatal("Signal was dropped")
+		}
+	})
+
+	t.Run("TTL should drop behind the first signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, TTL(Infinite))
+		s.Send([]byte{2})
+		s.Send([]byte{3}, TTL(Infinite))
+
+		_drop()
+
+		if v := _scan(nil); len(v) != 2 || v[0] != 1 || v[1] != 3 {
+			t.Fatal("Signals were not dropped")
+		}
+
+		if s.StatCount != 2 {
+			t.Fatal("Count is not correct")
+		}
+	})
+
+	t.Run("TTL should keep states of signals dropped behind", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, TTL(Infinite))
+		s.Send([]byte{2})
+		s.Send([]byte{3}, TTL(Infinite))
+
+		x := s.root.next.next
+
+		_drop()
+
+		// state not yet moved by the drop
+		s.states.m[string(_foo)] = x
+		s.states.m[string(_bar)] = x
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not kept")
+		}
+
+		if v, _, ok := s.Group(_bar).claim(); !ok || v.data[0] != 3 {
+			t.Fatal("Group state was not kept")
+		}
+	})
+
+	t.Run("TTL should reset the head", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, TTL(Infinite))
+		s.Send([]byte{2})
+
+		x := s.root.next
+
+		_drop()
+
+		if s.head != x || x.next != s.root {
+			t.Fatal("Head was not reset")
+		}
+	})
+
+	t.Run("TTL should move states of dropped signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, TTL(Infinite))
+		s.Send([]byte{2})
+
+		_scan(_foo)
+		_drop()
+
+		s.Send([]byte{3})
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not moved")
+		}
+	})
+
+	t.Run("TTL should continue scans behind dropped signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, TTL(Infinite))
+		s.Send([]byte{2})
+		s.Send([]byte{3}, TTL(Infinite))
+
+		x := s.root.next.next
+
+		_drop()
+
+		s.RLock()
+		n := s.next(x, 2)
+		s.RUnlock()
+
+		if n.data[0] != 3 {
+			t.Fatal("Scan was not continued")
+		}
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	t.Run("Headers should be delivered with the signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		h := map[string]string{"type": "text/plain"}
+
+		s.Send(_foo, Headers(h))
+
+		h["type"] = "" // must be copied
+
+		ch := make(chan Message, 1)
+
+		s.ScanMessages(context.Background(), ch, nil)
+
+		if m := <-ch; m.Header["type"] != "text/plain" {
+			t.Fatal("Header is not correct")
+		}
+	})
+
+	t.Run("Headers should be restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send(_foo, Headers(map[string]string{"a": "1", "b": "2"}))
+
+		s2 := _open(t, dir)
+
+		if h := s2.head.header; len(h) != 2 || h["a"] != "1" || h["b"] != "2" {
+			t.Fatal("Header was not restored")
+		}
+	})
+}
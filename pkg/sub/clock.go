@@ -0,0 +1,74 @@
+package sub
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Clock provides the current time of a space
+// in milliseconds since epoch.
+type Clock interface {
+	// Now returns the current time.
+	Now() int64
+}
+
+// A ManualClock is a clock, that only advances when told so.
+// It makes retention and time to live deterministic, e.g. for
+// testing. It is safe for concurrent use.
+type ManualClock struct {
+	// Current time.
+	now int64
+}
+
+// NewManualClock returns a new manual clock set to the
+// given time in milliseconds since epoch.
+func NewManualClock(t int64) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (c *ManualClock) Now() int64 {
+	return atomic.LoadInt64(&c.now)
+}
+
+// Set sets the clock to the given time in milliseconds since epoch.
+func (c *ManualClock) Set(t int64) {
+	atomic.StoreInt64(&c.now, t)
+}
+
+// Add advances the clock by the given duration in milliseconds.
+func (c *ManualClock) Add(d int64) {
+	atomic.AddInt64(&c.now, d)
+}
+
+// A ticker is the internal clock of a space. It caches the
+// current time, so that the operating systems time functions
+// will not be called for every signal.
+type ticker struct {
+	// Current time.
+	now int64
+}
+
+// Now returns the cached time.
+func (t *ticker) Now() int64 {
+	return atomic.LoadInt64(&t.now)
+}
+
+// Run stores the time value as milliseconds since epoch with an
+// accuracy of a microsecond, until the given channel is closed.
+//
+// This time value does contain any time zone information.
+func (t *ticker) run(done <-chan struct{}) {
+	c := time.NewTicker(time.Microsecond)
+
+	defer c.Stop()
+
+	for {
+		select {
+		case v := <-c.C:
+			atomic.StoreInt64(&t.now, v.UnixMilli())
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package sub
+
+import (
+	"testing"
+)
+
+func TestWithClock(t *testing.T) {
+	t.Run("WithClock should set the clock", func(t *testing.T) {
+		c := NewManualClock(_epoch)
+
+		s := NewSpace(WithClock(c))
+
+		defer s.Close()
+
+		s.Send(_foo)
+
+		if s.head.time != _epoch {
+			t.Fatal("Time is not correct")
+		}
+
+		if s.Topic("foo").clock != c {
+			t.Fatal("Clock is not shared")
+		}
+	})
+}
+
+func TestManualClock(t *testing.T) {
+	t.Run("ManualClock should only advance when told so", func(t *testing.T) {
+		c := NewManualClock(_epoch)
+
+		if c.Now() != _epoch {
+			t.Fatal("Time is not correct")
+		}
+
+		c.Add(10)
+
+		if c.Now() != _epoch+10 {
+			t.Fatal("Time was not added")
+		}
+
+		c.Set(1)
+
+		if c.Now() != 1 {
+			t.Fatal("Time was not set")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package sub
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrClosed is returned for operations on a closed space.
+var ErrClosed = errors.New("space closed")
+
+// Close closes the space with all of its topics. The internal clock will be
+// stopped, all signals and states will be released and all blocked watchers
+// and senders will be woken. The journal, if any, will be synced and closed,
+// but its segments will be kept, so that the space can be opened again.
+//
+// Further sends, scans and restores will return ErrClosed, further drops
+// will do nothing. Close returns the first error that occurred while writing the
+// journal, if any, or ErrClosed, if the space was already closed.
+func (s *Space) Close() error {
+	t := s.topics
+
+	t.Lock()
+
+	if s.stopped() {
+		t.Unlock()
+		return ErrClosed
+	}
+
+	close(t.done)
+
+	l := []*Space{t.space}
+
+	for _, x := range t.m {
+		l = append(l, x)
+	}
+
+	t.Unlock()
+
+	for _, x := range l {
+		x.shutdown()
+	}
+
+	if t.space.journal != nil {
+		return t.space.journal.close()
+	}
+
+	return nil
+}
+
+// Shutdown releases all signals and states of the space
+// and wakes all blocked watchers and senders.
+func (s *Space) shutdown() {
+	s.tail.Lock()
+	s.Lock()
+
+	for x := s.root.next; x != s.root; {
+		n := x.next
+
+		s.free(x)
+
+		x = n
+	}
+
+	s.root.next, s.head, s.keys = s.root, s.root, nil
+
+	s.wakeup()
+	s.release()
+	s.topics.release()
+
+	s.Unlock()
+	s.tail.Unlock()
+
+	s.states.Lock()
+	clear(s.states.m)
+	clear(s.states.leases)
+	s.states.Unlock()
+
+	atomic.AddUint64(&s.ops, 1)
+}
+
+// Stopped reports whether the space was closed.
+func (s *Space) stopped() bool {
+	select {
+	case <-s.topics.done:
+		return true
+	default:
+		return false
+	}
+}
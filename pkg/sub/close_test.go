@@ -0,0 +1,198 @@
+package sub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	t.Run("Close should stop the clock", func(t *testing.T) {
+		s := NewSpace()
+
+		s.Close()
+
+		n := s.clock.Now()
+
+		time.Sleep(10 * time.Millisecond)
+
+		if s.clock.Now() != n {
+			t.Fatal("Clock was not stopped")
+		}
+	})
+
+	t.Run("Close should release signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+		_scan(_foo)
+
+		s.SendTopic("foo", []byte{2})
+		s.Close()
+
+		if s.root.next != s.root || s.head != s.root {
+			t.Fatal("Signals were not released")
+		}
+
+		if s.StatCount != 0 || s.StatAlloc != 0 {
+			t.Fatal("Stats are not correct")
+		}
+
+		if len(s.States()) != 0 {
+			t.Fatal("States were not released")
+		}
+
+		if s.Topic("foo").head != s.Topic("foo").root {
+			t.Fatal("Topic was not released")
+		}
+	})
+
+	t.Run("Close should wake watchers", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		ch := make(chan []byte)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		s.Watch(context.Background(), ch, _foo)
+
+		if _, ok := <-ch; ok {
+			t.Fatal("Channel was not closed")
+		}
+	})
+
+	t.Run("Close should wake scanners blocked on the channel", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		if _, err := s.ScanContext(context.Background(), make(chan []byte), _foo); !errors.Is(err, ErrClosed) {
+			t.Fatal("Error is not correct")
+		}
+	})
+
+	t.Run("Close should wake watchers blocked on the channel", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		s.Watch(context.Background(), make(chan []byte), _foo)
+	})
+
+	t.Run("Close should wake group scanners blocked on the channel", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		if _, err := s.Group(_foo).Scan(context.Background(), make(chan Message)); !errors.Is(err, ErrClosed) {
+			t.Fatal("Error is not correct")
+		}
+	})
+
+	t.Run("Close should wake group watchers blocked on the channel", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		s.Group(_foo).Watch(context.Background(), make(chan Message))
+	})
+
+	t.Run("Close should wake blocked senders", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, Block)
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			s.Close()
+		}()
+
+		if _, err := s.TrySend([]byte{2}); !errors.Is(err, ErrClosed) {
+			t.Fatal("Error is not correct")
+		}
+	})
+
+	t.Run("Close should fail further operations", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Close()
+
+		if _, err := s.TrySend([]byte{1}); !errors.Is(err, ErrClosed) {
+			t.Fatal("Send did not fail")
+		}
+
+		if _, err := s.ScanContext(context.Background(), make(chan []byte, 1), _foo); !errors.Is(err, ErrClosed) {
+			t.Fatal("Scan did not fail")
+		}
+
+		if err := s.Close(); !errors.Is(err, ErrClosed) {
+			t.Fatal("Close did not fail")
+		}
+	})
+
+	t.Run("Close should keep the journal", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+
+		if err := s1.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		s1.Drop(_now)
+
+		if s2 := _open(t, dir); s2.StatCount != 1 {
+			t.Fatal("Journal was not kept")
+		}
+	})
+}
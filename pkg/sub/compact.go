@@ -0,0 +1,85 @@
+package sub
+
+import (
+	"sync/atomic"
+)
+
+// Compact turns the key-based compaction mode of the space on or off.
+// In this mode, a signal sent with a key supersedes all older signals
+// with the same key. Superseded signals will be skipped by all scans
+// and removed by the next call to Drop, so that a new scanner gets the
+// latest signal for each key instead of the full history. Signals
+// without a key will never be superseded.
+//
+// If called on the default topic, all other topics will be compacted
+// too, including all topics created afterwards.
+func (s *Space) Compact(on bool) {
+	s.compacting(on)
+
+	if s.topic == "" {
+		s.topics.RLock()
+
+		for _, t := range s.topics.m {
+			t.compacting(on)
+		}
+
+		s.topics.RUnlock()
+	}
+}
+
+// Key sets the key of a signal for the key-based compaction mode.
+func Key(k string) Attr {
+	return func(x *signal) {
+		x.key = k
+	}
+}
+
+// Compacting sets the compaction mode of the space and
+// indexes or releases all signals of the space.
+func (s *Space) compacting(on bool) {
+	s.tail.Lock()
+	defer s.tail.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.compact, s.keys, s.stale = on, nil, 0
+
+	for x := s.root.next; x != s.root; x = x.next {
+		x.stale = 0
+
+		s.index(x)
+	}
+}
+
+// Index marks all older signals with the same key as the given signal
+// as superseded, if the space is compacted.
+//
+// The tail must be locked.
+func (s *Space) index(x *signal) {
+	if !s.compact || x.key == "" {
+		return
+	}
+
+	if s.keys == nil {
+		s.keys = make(map[string]*signal)
+	}
+
+	if p, ok := s.keys[x.key]; ok {
+		atomic.StoreUint32(&p.stale, 1)
+		s.stale++
+	}
+
+	s.keys[x.key] = x
+}
+
+// Unindex removes the given signal from the index before it is dropped.
+//
+// The space and its tail must be locked.
+func (s *Space) unindex(x *signal) {
+	if x.stale != 0 {
+		s.stale--
+	} else if s.keys[x.key] == x {
+		delete(s.keys, x.key)
+	}
+}
@@ -0,0 +1,86 @@
+package sub
+
+import (
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	t.Run("Compact should skip superseded signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Compact(true)
+
+		s.Send([]byte{1}, Key("a"))
+		s.Send([]byte{2}, Key("b"))
+		s.Send([]byte{3}, Key("a"))
+		s.Send([]byte{4})
+
+		if v := _scan(nil); len(v) != 3 || v[0] != 2 || v[1] != 3 {
+			t.Fatal("Signals were not compacted")
+		}
+	})
+
+	t.Run("Compact should drop superseded signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Compact(true)
+
+		s.Send([]byte{1}, Key("a"))
+		s.Send([]byte{2}, Key("a"))
+
+		s.Drop(Infinite)
+
+		if s.StatCount != 1 || s.stale != 0 {
+			t.Fatal("Signal was not dropped")
+		}
+	})
+
+	t.Run("Compact should index existing signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send([]byte{1}, Key("a"))
+		s.Send([]byte{2}, Key("a"))
+
+		if v := _scan(_foo); len(v) != 2 {
+			t.Fatal("Signals were compacted")
+		}
+
+		s.Compact(true)
+
+		if v := _scan(nil); len(v) != 1 || v[0] != 2 {
+			t.Fatal("Signals were not compacted")
+		}
+	})
+
+	t.Run("Compact should apply to topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Compact(true)
+
+		if !s.Topic("foo").compact {
+			t.Fatal("Topic is not compacted")
+		}
+	})
+}
+
+func TestKey(t *testing.T) {
+	t.Run("Key should be restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send(_foo, Key("a"))
+
+		if s2 := _open(t, dir); s2.head.key != "a" {
+			t.Fatal("Key was not restored")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package sub
+
+import (
+	"context"
+	"math"
+)
+
+// A cursor is a position inside of a space, used to iterate over
+// its signals without locking the space between two signals.
+type cursor struct {
+	// Iterated space.
+	s *Space
+	// Signal of the current position.
+	x *signal
+	// Sequence number of the current signal, to detect its reuse.
+	q uint64
+	// Sequence number of the last signal to iterate, the maximum for no end.
+	e uint64
+	// Maximum count of signals to write, 0 for no limit.
+	count int
+	// Maximum size of signals to write, 0 for no limit.
+	size int
+}
+
+// Cursor returns a new cursor positioned at the given state.
+// If end is given, the cursor will stop at the current head, even if
+// the head is dropped, evicted or superseded meanwhile.
+func (s *Space) cursor(state []byte, end bool) (c *cursor) {
+	c = &cursor{s: s, e: math.MaxUint64}
+
+	c.x, c.q = s.load(state)
+
+	if end {
+		s.tail.Lock()
+		c.e = s.seq
+		s.tail.Unlock()
+	}
+
+	return
+}
+
+// Peek returns the signal following the current position and a copy
+// of it, taken while the space was locked. Superseded signals will be
+// skipped. It reports whether there is such a signal before the end.
+func (c *cursor) peek() (x *signal, v signal, ok bool) {
+	c.s.RLock()
+
+	// skip superseded signals
+	for x = c.s.next(c.x, c.q); x.superseded(); x = x.link() {
+	}
+
+	v = x.load()
+	c.s.RUnlock()
+
+	return x, v, x != c.s.root && v.seq <= c.e
+}
+
+// Seek moves the cursor to the given signal and its copy.
+func (c *cursor) seek(x *signal, v signal) {
+	c.x, c.q = x, v.seq
+}
+
+// Scan writes all signals up to the end of the given cursor to the given
+// channel, converted by the given function, until the given context is done.
+// Signals not selected by the given filters will be skipped. The scan will
+// stop before a signal that would exceed the limits of the cursor, but will
+// always write at least one signal. It returns the contexts error, if the
+// scan was aborted, or ErrClosed, if the space was closed before or while
+// waiting for the channel.
+func scan[T any](ctx context.Context, c *cursor, ch chan<- T, conv func(*signal) T, filters []Filter) error {
+	if c.s.stopped() {
+		return ErrClosed
+	}
+
+	for n, b := 0, 0; ; {
+		x, v, ok := c.peek()
+
+		if !ok {
+			return nil
+		}
+
+		if !v.selected(filters) {
+			c.seek(x, v)
+			continue
+		}
+
+		if n, b = n+1, b+len(v.data); n > 1 && c.exceeds(n, b) {
+			return nil
+		}
+
+		select {
+		case ch <- conv(&v):
+			c.seek(x, v)
+		case <-c.s.topics.done:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Value returns the data of the signal.
+func (x *signal) value() []byte {
+	return x.data
+}
+
+// Exceeds reports whether the given count and size of signals
+// exceed the limits of the cursor.
+func (c *cursor) exceeds(n, b int) bool {
+	return (c.count > 0 && n > c.count) || (c.size > 0 && b > c.size)
+}
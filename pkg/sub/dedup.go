@@ -0,0 +1,132 @@
+package sub
+
+import (
+	"bytes"
+	"errors"
+	"hash/maphash"
+)
+
+// ErrDuplicate is returned for signals dropped as duplicates.
+var ErrDuplicate = errors.New("duplicate signal")
+
+// Seed of the content hashes of signals.
+var seed = maphash.MakeSeed()
+
+// A dedup key identifies a signal by its producer id or,
+// if it has none, by the hash of its data.
+type dedup struct {
+	// Producer id, empty for none.
+	id string
+	// Hash of the data, zero if identified by id.
+	sum uint64
+}
+
+// WithDedup drops every signal sent to the space or one of its topics,
+// that was already sent within the given window in milliseconds. Signals
+// are identified by their producer id, if given, or else by their data.
+// Dropped duplicates are rejected with ErrDuplicate.
+func WithDedup(window int64) Option {
+	return func(s *Space) {
+		s.window = window
+	}
+}
+
+// ID sets the producer id of a signal for the deduplication window.
+// Signals with the same id are duplicates, regardless of their data.
+func ID(id string) Attr {
+	return func(x *signal) {
+		x.id = id
+	}
+}
+
+// Dedup returns the dedup key of the signal.
+func (x *signal) dedup() dedup {
+	if x.id != "" {
+		return dedup{id: x.id}
+	}
+
+	return dedup{sum: maphash.Bytes(seed, x.data)}
+}
+
+// Unique returns the given signals with the given total size without all
+// signals already seen within the dedup window, including duplicates inside
+// the given signals, and the offset of the last duplicate, if any. It returns
+// ErrDuplicate, if all signals were duplicates.
+//
+// The tail must be locked.
+func (s *Space) unique(l []*signal, n int) ([]*signal, int, uint64, error) {
+	if s.window <= 0 {
+		return l, n, 0, nil
+	}
+
+	var q uint64
+
+	u, now, batch := l[:0], s.clock.Now(), make(map[dedup]*signal)
+
+	for _, x := range l {
+		k := x.dedup()
+
+		if p, ok := s.seen[k]; ok && now-p.time < s.window && x.same(p) {
+			n, q = n-len(x.data), p.seq
+			continue
+		}
+
+		if p, ok := batch[k]; ok && x.same(p) {
+			n -= len(x.data)
+			continue
+		}
+
+		batch[k], u = x, append(u, x)
+	}
+
+	if len(u) == 0 {
+		return u, n, q, ErrDuplicate
+	}
+
+	return u, n, q, nil
+}
+
+// Same reports whether the signal is a duplicate of the given signal
+// with the same dedup key.
+func (x *signal) same(p *signal) bool {
+	return x.id != "" || bytes.Equal(x.data, p.data)
+}
+
+// Remember adds the given signal to the dedup index, if deduplicated.
+//
+// The tail must be locked.
+func (s *Space) remember(x *signal) {
+	if s.window <= 0 {
+		return
+	}
+
+	if s.seen == nil {
+		s.seen = make(map[dedup]*signal)
+	}
+
+	s.seen[x.dedup()] = x
+}
+
+// Forget removes the given signal from the dedup index before it is dropped.
+//
+// The space and its tail must be locked.
+func (s *Space) forget(x *signal) {
+	if s.window <= 0 {
+		return
+	}
+
+	if k := x.dedup(); s.seen[k] == x {
+		delete(s.seen, k)
+	}
+}
+
+// Expire removes all signals older than the dedup window from the index.
+//
+// The space and its tail must be locked.
+func (s *Space) expire(now int64) {
+	for k, x := range s.seen {
+		if now-x.time >= s.window {
+			delete(s.seen, k)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package sub
+
+import (
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	t.Run("Dedup should drop duplicate signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1})
+		s.Send([]byte{2})
+
+		if _, err := s.TrySend([]byte{1}); err != ErrDuplicate {
+			t.Fatal("Error is not correct")
+		}
+
+		if v := _scan(nil); len(v) != 2 || v[0] != 1 || v[1] != 2 {
+			t.Fatal("Signals were not deduplicated")
+		}
+	})
+
+	t.Run("Dedup should return the offset of the duplicate", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1})
+		s.Send([]byte{2})
+
+		if q, _ := s.Append([]byte{1}); q != 1 {
+			t.Fatal("Offset is not correct")
+		}
+	})
+
+	t.Run("Dedup should drop signals with the same id", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1}, ID("a"))
+		s.Send([]byte{2}, ID("a"))
+		s.Send([]byte{1}, ID("b"))
+
+		if v := _scan(nil); len(v) != 2 || v[0] != 1 || v[1] != 1 {
+			t.Fatal("Signals were not deduplicated")
+		}
+	})
+
+	t.Run("Dedup should drop duplicates inside a batch", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1})
+		s.SendBatch([][]byte{{1}, {2}, {2}, {3}})
+
+		if v := _scan(nil); len(v) != 3 || v[1] != 2 || v[2] != 3 {
+			t.Fatal("Batch was not deduplicated")
+		}
+	})
+
+	t.Run("Dedup should keep signals after the window", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1})
+
+		_tick(100)
+
+		s.Send([]byte{1})
+
+		if v := _scan(nil); len(v) != 2 {
+			t.Fatal("Signal was deduplicated")
+		}
+	})
+
+	t.Run("Dedup should clean up the index on drop", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		s.Send([]byte{1})
+		s.Send([]byte{2})
+
+		_tick(50)
+
+		s.Drop(10)
+
+		if len(s.seen) != 0 {
+			t.Fatal("Index was not cleaned up")
+		}
+
+		s.Send([]byte{3})
+
+		_tick(100)
+
+		s.Drop(Infinite)
+
+		if len(s.seen) != 0 || s.StatCount != 1 {
+			t.Fatal("Index was not cleaned up")
+		}
+	})
+
+	t.Run("Dedup should apply to topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _dedup(100)
+
+		if s.Topic("foo").window != 100 {
+			t.Fatal("Topic is not deduplicated")
+		}
+	})
+}
+
+func TestID(t *testing.T) {
+	t.Run("ID should be restored", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir, WithDedup(60000))
+
+		s1.Send(_foo, ID("a"))
+
+		s2 := _open(t, dir, WithDedup(60000))
+
+		if _, err := s2.TrySend(_bar, ID("a")); err != ErrDuplicate {
+			t.Fatal("ID was not restored")
+		}
+	})
+}
+
+func _dedup(window int64) *Space {
+	s := NewSpace(WithClock(NewManualClock(_epoch)), WithDedup(window))
+
+	_s.Swap(s).Close()
+
+	return s
+}
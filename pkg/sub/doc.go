@@ -14,6 +14,43 @@
 // be used at all times. If a state points to a signal out of retention time, it will be removed automatically with
 // the next call of the Drop method.
 //
+// While waiting for a channel, neither Scan nor ScanContext will lock the subspace, so a slow consumer will never
+// stall any other operation. ScanContext can be used to abort a scan, if a consumer is gone. The state will then
+// point to the last signal that was written to the channel.
+//
+// ScanContext and ScanMessages can be given filters, so that only the selected signals will be written to the channel.
+// Filters can select signals by a prefix, a substring, a regular expression, a header value or a time window. The
+// state will still be advanced past all skipped signals. Custom filters are functions selecting a signal by its
+// message. Filters can also be parsed from terms via ParseFilter:
+//
+//	s.ScanContext(ctx, ch, []byte("foo"), sub.Prefix([]byte("bar")))
+//
+// A consumer that was offline for a while should not be flooded with its backlog. ScanPage stops a scan before a
+// maximum count of signals or a maximum size of their data would be exceeded, and only advances the state to the
+// last signal written to the channel. Scanning again with the same state will return the next page.
+//
+// By default, a scan advances its state as soon as a signal has been written to the channel, regardless of whether
+// the consumer has processed it. For at-least-once delivery, a batch of signals can be leased via Lease instead. The
+// state will then only be advanced by acknowledging the offset of the last processed signal via Ack. If a lease
+// expires before all of its signals have been acknowledged, the remaining signals will be delivered again:
+//
+//	s.Lease(ctx, ch, []byte("foo"), 100, 0, 5000)
+//	s.Ack([]byte("foo"), m.Offset)
+//
+// Instead of a channel, signals can also be scanned by iterating over Signals or TimedSignals. The state will then be
+// saved pointing to the last yielded signal, as soon as the iteration completes or breaks:
+//
+//	for x := range s.Signals([]byte("foo")) {
+//		fmt.Println(string(x))
+//	}
+//
+// Signals can also be watched. Watching works the same way as scanning, but after all existing signals have been
+// written to the channel, Watch will keep on writing newly sent signals to it, until the given context is done. The
+// state will be advanced with every written signal, so that a following Watch or Scan will continue right after the
+// last signal written to the channel:
+//
+//	go s.Watch(ctx, ch, []byte("foo"))
+//
 // # Altering Operations
 //
 // Every time a data structure altering operation (as Send or Drop) is called, the internal operations counter will
@@ -29,6 +66,78 @@
 //	s.Drop(0)
 //	// Will return 2
 //
+// # Offsets
+//
+// Every signal sent to a topic gets an offset, starting with one and increasing by one with every signal. Offsets are
+// never reused, not even after a signal was dropped, so they can be used to address a signal, to checkpoint a
+// consumer by number or to detect duplicates. Append returns the offset of the sent signal, ScanMessages delivers
+// each signal along with its offset and time:
+//
+//	o, err := s.Append([]byte("foo"))
+//	m, ok := s.Lookup(o)
+//
+// A state can be positioned at an offset via SeekOffset and all signals from an offset on can be scanned without
+// a state via ScanFrom.
+//
+// # Batches
+//
+// Many small signals can be sent at once via SendBatch. All signals of a batch are appended in one operation with
+// consecutive offsets, so scanners will see either all or none of them. A batch exceeding the limits of the subspace
+// will be rejected as a whole:
+//
+//	_, err := s.SendBatch([][]byte{[]byte("foo"), []byte("bar")})
+//
+// # Headers
+//
+// A signal can also be sent with a header of key value pairs, e.g. for its content type, origin or trace id. The
+// header will be delivered along with the signal by ScanMessages and persisted together with the signal:
+//
+//	s.Send([]byte("foo"), sub.Headers(map[string]string{"type": "text/plain"}))
+//
+// # Compaction
+//
+// For feeds where only the latest signal per key matters, a subspace can be compacted. A signal sent with a key will
+// then supersede all older signals with the same key. Superseded signals will be skipped by all scans and removed by
+// the next call to Drop, so that a new scanner gets the latest signal for each key instead of the full history:
+//
+//	s.Compact(true)
+//	s.Send([]byte("on"), sub.Key("foo"))
+//
+// # Time To Live
+//
+// By default, all signals will be dropped after the retention time given to Drop. A signal can also be sent with its
+// own time to live, so that short-lived and long-lived signals can coexist in one subspace. Such a signal will be
+// dropped as soon as it is older than its time to live, regardless of the retention time given to Drop:
+//
+//	s.Send([]byte("foo"), sub.TTL(1000))
+//
+// As long as there are no signals with their own time to live, Drop will only sweep the oldest signals at the front of
+// the chain. Otherwise, the whole chain will be swept. States pointing to a signal dropped behind the front of the
+// chain, will be moved to the signal before.
+//
+// # Deduplication
+//
+// Retrying producers or multiple relays may send the same signal more than once. A subspace created with a dedup
+// window in milliseconds will drop every signal already sent within the window, identified by its producer id, if
+// given, or else by its data. Dropped duplicates are rejected with ErrDuplicate, Append returns the offset of the
+// original signal. The dedup index is cleaned up by Drop:
+//
+//	s := sub.NewSpace(sub.WithDedup(1000))
+//	s.Send([]byte("foo"), sub.ID("bar"))
+//
+// # Options
+//
+// A subspace can be configured on creation by options. Instead of calling Drop periodically, a subspace can drop its
+// signals automatically in the background, using a default retention time in milliseconds. Limits and a clock can be
+// given as well:
+//
+//	s := sub.NewSpace(sub.WithRetention(60000), sub.WithAutoDrop(time.Second))
+//
+// Applications embedding a subspace can react on its events via hooks. Hooks are called after a signal was sent, after
+// signals were dropped or evicted and after a state was created or deleted:
+//
+//	s := sub.NewSpace(sub.WithHooks(sub.Hooks{OnSend: func(topic string, m sub.Message) {}}))
+//
 // # Internal Clock
 //
 // A subspace operates its own internal clock with an accuracy of a microsecond. Signals that concurrently arrive at
@@ -36,24 +145,64 @@
 // not return until the clocks first tick has occurred. So the minimum duration time of this method is one tenth of a
 // millisecond.
 //
+// The clock will run until the subspace is closed. Close stops the clock, releases all signals and states and wakes
+// all blocked watchers and senders. Any further send or scan will then fail with ErrClosed:
+//
+//	defer s.Close()
+//
+// Instead of its internal clock, a subspace can also be given its own clock. A ManualClock only advances when told so,
+// which makes retention times and times to live deterministic, e.g. for testing:
+//
+//	c := sub.NewManualClock(0)
+//	s := sub.NewSpace(sub.WithClock(c))
+//	c.Add(1000)
+//
 // # Internal Statistics
 //
-// Statistic about the subspaces current state can be retrieved via the structures public fields. These fields are not
-// safe for concurrent use, not even reading. Please consider to use an atomic operation like LoadUint64 to retrieve a
-// value:
+// A consistent snapshot of statistics about the subspaces current state can be retrieved via Stats. Besides the count
+// of signals and their allocated memory, it contains the operations count, the count of states, the age of the oldest
+// and the newest signal and how many signals have been dropped, also as a rate of the last drop:
+//
+//	st := s.Stats()
+//
+// Stats only cover a single topic. The statistics of all topics summed up can be retrieved via TotalStats.
+//
+// The count of signals and their allocated memory can also be retrieved via the structures public fields. These fields
+// are not safe for concurrent use, not even reading. Please consider to use an atomic operation like LoadUint64 to
+// retrieve a value:
 //
 //	sc := atomic.LoadUint64(&s.StatCount)
 //	sa := atomic.LoadUint64(&s.StatAlloc)
 //
-// There are two public available statistics of a subspace:
+// # Limits
+//
+// By default, a subspace is only limited by its retention time. A subspace can also be limited to a maximum count of
+// signals and a maximum allocated memory. If a sent signal would exceed a limit, the subspaces policy decides, whether
+// the oldest signals will be evicted, the sent signal will be rejected or the sender will be blocked until signals
+// have been dropped. Rejected signals are reported by TrySend with ErrFull:
+//
+//	s.Limit(1000, 1<<20, sub.Reject)
 //
-//  1. StatCount: Number of currently stored signals.
-//  2. StatAlloc: Number of currently allocated memory (in bytes).
+// Limits apply to every topic on its own. To bound the memory of a subspace regardless of its count of topics, all
+// topics together can be limited on creation by totals, evicting the oldest signals of all topics:
 //
-// # No Persistence
+//	s := sub.NewSpace(sub.WithTotal(100000, 1<<30))
 //
-// As a subspace is a memory only data structure, all signals will not be persisted. If this is required, then the task
-// is in the responsibility of the data structures user.
+// # Persistence
+//
+// As a subspace is a memory only data structure by default, all signals will not be persisted. If this is required, a
+// subspace can be opened with a journal instead. A journal is an append-only write-ahead log, divided into segments
+// and stored in the given directory. Every sent signal, with its internal time, and every saved state will be written
+// to the journal. Opening a subspace will restore all signals and states found in the journal. Journal segments will
+// be removed, as soon as all of their signals have been dropped:
+//
+//	s, err := sub.Open("/var/lib/subspace")
+//
+// Journal records will be handed to the operating system immediately, but will only be committed to the disk by
+// calling Sync. Records after a damaged record, e.g. by a crash while writing, will be ignored.
+//
+// Besides a journal, a point-in-time copy of all signals and states can be written via Snapshot and later be loaded
+// into an empty subspace via Restore.
 //
 // # State Management
 //
@@ -64,10 +213,40 @@
 // A forked state can also be forked. Its name will begin with two exclamation marks (!!). Forking a state is a really
 // powerful concept, as it allows a subspace state to be used without altering it.
 //
-// It is possible to fast forward a state, by simply scanning and ignoring any found signals. It is not possible to
-// rewind a state to the first signal of a subspace. If you have to scan signals twice, you should consider forking
+// It is possible to fast forward a state, by simply scanning and ignoring any found signals. A state can also be moved
+// to a point in time, backwards or forwards, via Seek. The next scan using this state will then start with the first
+// signal received at or after the given time. To scan signals since a point in time without any state, use ScanSince:
+//
+//	s.Seek([]byte("foo"), time.Now().Add(-time.Minute).UnixMilli())
+//	s.ScanSince(make(chan []byte, 1), time.Now().Add(-time.Minute).UnixMilli())
+//
+// The states of a subspace can be managed explicitly. States lists all state names, Lag returns how many signals a
+// state is behind the head, Delete removes a state and Copy or Rename save a state under another name:
+//
+//	s.Copy([]byte("foo"), []byte("bar"))
+//
+// Signals already dropped can not be scanned again. If you have to scan signals twice, you should consider forking
 // the state beforehand, using a different state name, or using no state (nil) at all.
 //
+// Several consumers scanning with the same state would each get overlapping signals. To share a stream of signals
+// between several consumers instead, they can join a consumer group. Every signal will then be handed to exactly one
+// member of the group. A group is stored as a normal state under its name:
+//
+//	go s.Group([]byte("foo")).Watch(ctx, ch)
+//
+// # Topics
+//
+// A subspace can be divided into named topics. Each topic has its own chain of signals and its own namespace of
+// states, so that different producers and consumers can share one subspace without scanning each others signals.
+// A topic is simply created by using it the first time. The space returned by NewSpace is the default topic, which
+// is addressed by the empty name. All topics of a subspace share the same internal clock:
+//
+//	s.SendTopic("foo", []byte("bar"))
+//	s.ScanTopic(make(chan []byte, 1), "foo", nil)
+//
+// Calling Drop on the default topic will drop the signals of all topics. Calling Drop on any other topic or calling
+// DropTopic will only drop the signals of that topic.
+//
 // # Performance Optimizations
 //
 // To increase the maximum possible performance of a subspace, various aids have been implemented:
@@ -78,9 +257,9 @@
 //     root signal for faster iteration. Thereby, a subspace is internally a looped directional graph.
 //  2. A concurrent internal clock is used to prevent calls to the operating systems time functions directly. Instead,
 //     all calls for the current time will use a cached variable with an atomic operation.
-//  3. A sync pool for signal structures is used to avoid memory allocations while receiving signals. All used signal
-//     structures will return to the structure pool, after they have been dropped. The pool will come into full effect
-//     for all received signals, right after a call to the Drop method and before the next garbage collector run.
+//  3. Senders only lock the tail of the chain against each other. A sent signal is linked to the chain atomically, so
+//     that scans never block senders and senders never block scans. Only Drop and the eviction of signals will lock
+//     the whole chain.
 //
 // # License
 //
@@ -0,0 +1,121 @@
+package sub
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Filter selects the signals of a scan by their messages. Signals not
+// selected by all given filters will be skipped, but the state will still
+// be advanced past them. A filter must not modify the given message.
+type Filter func(m Message) bool
+
+// Error for filter terms that can not be parsed.
+var ErrFilter = errors.New("invalid filter")
+
+// Prefix selects all signals whose data begins with the given prefix.
+func Prefix(p []byte) Filter {
+	return func(m Message) bool {
+		return bytes.HasPrefix(m.Data, p)
+	}
+}
+
+// Contains selects all signals whose data contains the given bytes.
+func Contains(b []byte) Filter {
+	return func(m Message) bool {
+		return bytes.Contains(m.Data, b)
+	}
+}
+
+// Match selects all signals whose data matches the given regular expression.
+func Match(re *regexp.Regexp) Filter {
+	return func(m Message) bool {
+		return re.Match(m.Data)
+	}
+}
+
+// Header selects all signals with the given header value.
+func Header(key, value string) Filter {
+	return func(m Message) bool {
+		v, ok := m.Header[key]
+
+		return ok && v == value
+	}
+}
+
+// Window selects all signals received at or after from and before to,
+// both given in milliseconds since epoch. A zero stands for no bound.
+func Window(from, to int64) Filter {
+	return func(m Message) bool {
+		return m.Time >= from && (to == 0 || m.Time < to)
+	}
+}
+
+// ParseFilter parses a single filter term of the form kind:value. Known
+// kinds are prefix, contains, regex, header (with a key=value) as well as
+// from and to (with milliseconds since epoch). It returns ErrFilter, if
+// the term can not be parsed.
+func ParseFilter(term string) (Filter, error) {
+	k, v, ok := strings.Cut(term, ":")
+
+	if !ok {
+		return nil, ErrFilter
+	}
+
+	switch k {
+	case "prefix":
+		return Prefix([]byte(v)), nil
+
+	case "contains":
+		return Contains([]byte(v)), nil
+
+	case "regex":
+		re, err := regexp.Compile(v)
+
+		if err != nil {
+			return nil, ErrFilter
+		}
+
+		return Match(re), nil
+
+	case "header":
+		if k, v, ok := strings.Cut(v, "="); ok {
+			return Header(k, v), nil
+		}
+
+	case "from", "to":
+		t, err := strconv.ParseInt(v, 10, 64)
+
+		if err != nil {
+			return nil, ErrFilter
+		}
+
+		if k == "from" {
+			return Window(t, 0), nil
+		}
+
+		return Window(0, t), nil
+	}
+
+	return nil, ErrFilter
+}
+
+// Selected reports whether the signal is selected by all given filters.
+func (x *signal) selected(filters []Filter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	m := x.message()
+
+	for _, f := range filters {
+		if !f(m) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,107 @@
+package sub
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	t.Run("Prefix should select by prefix", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if v := _filter(Prefix([]byte("fo"))); len(v) != 1 || v[0] != "foo" {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Contains should select by substring", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if v := _filter(Contains([]byte("a"))); len(v) != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Match should select by regex", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if v := _filter(Match(regexp.MustCompile("^b.r$"))); len(v) != 1 || v[0] != "bar" {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Header should select by header", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if v := _filter(Header("k", "v")); len(v) != 1 || v[0] != "baz" {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Window should select by time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if v := _filter(Window(0, 1)); len(v) != 0 {
+			t.Fatal("Data is not empty")
+		}
+	})
+
+	t.Run("Filters should be written by callers", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		f := func(m Message) bool {
+			return m.Offset > 1 && m.Header == nil
+		}
+
+		if v := _filter(f); len(v) != 1 || v[0] != "bar" {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Filters should advance the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_filter(Prefix([]byte("fo")))
+
+		if v := _scan(_foo); len(v) != 0 {
+			t.Fatal("State was not advanced")
+		}
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Run("ParseFilter should parse all kinds", func(t *testing.T) {
+		for _, term := range []string{"prefix:a", "contains:a", "regex:a+", "header:a=b", "from:1", "to:1"} {
+			if _, err := ParseFilter(term); err != nil {
+				t.Fatal("Term was not parsed", term)
+			}
+		}
+	})
+
+	t.Run("ParseFilter should fail for invalid terms", func(t *testing.T) {
+		for _, term := range []string{"a", "foo:a", "regex:(", "header:a", "from:a"} {
+			if _, err := ParseFilter(term); err != ErrFilter {
+				t.Fatal("Term was parsed", term)
+			}
+		}
+	})
+}
+
+func _filter(f Filter) (l []string) {
+	s := _s.Load()
+
+	s.Send([]byte("foo"))
+	s.Send([]byte("bar"))
+	s.Send([]byte("baz"), Headers(map[string]string{"k": "v"}))
+
+	ch := make(chan []byte, 3)
+
+	s.ScanContext(context.Background(), ch, _foo, f)
+
+	for v := range ch {
+		l = append(l, string(v))
+	}
+
+	return
+}
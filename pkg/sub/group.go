@@ -0,0 +1,163 @@
+package sub
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// A Group is a consumer group sharing one state. Every signal will be
+// handed to exactly one member of the group, so that the processing of
+// a single stream of signals can be scaled horizontally. Members are
+// goroutines or processes, which scan or watch the same group.
+type Group struct {
+	// Scanned space.
+	s *Space
+	// Name of the shared state.
+	state []byte
+}
+
+// Group returns the consumer group with the given name. The group is
+// stored as a normal state under the same name, so it can be managed
+// like any other state.
+func (s *Space) Group(name []byte) *Group {
+	return &Group{s: s, state: name}
+}
+
+// Scan hands all signals up to the head, not yet claimed by any other member
+// of the group, to the given channel, until the given context is done or the
+// space is closed. The given channel will be closed. A signal claimed while
+// the context is done will not be handed to any other member.
+//
+// The space will not be locked while waiting for the channel.
+//
+// Scan will return the current spaces operations count
+// as a timestamp of the spaces internal signal state and the
+// contexts error, if the scan was aborted, or ErrClosed,
+// if the space was closed.
+func (g *Group) Scan(ctx context.Context, ch chan<- Message) (uint64, error) {
+	defer close(ch)
+
+	if g.s.stopped() {
+		return atomic.LoadUint64(&g.s.ops), ErrClosed
+	}
+
+	for {
+		v, _, ok := g.claim()
+
+		if !ok {
+			return atomic.LoadUint64(&g.s.ops), nil
+		}
+
+		select {
+		case ch <- v.message():
+		case <-g.s.topics.done:
+			return atomic.LoadUint64(&g.s.ops), ErrClosed
+		case <-ctx.Done():
+			return atomic.LoadUint64(&g.s.ops), ctx.Err()
+		}
+	}
+}
+
+// Watch hands signals to the given channel the same way as Scan, but will
+// keep on waiting for newly sent signals, until the given context is done
+// or the space is closed.
+//
+// This should be run as a goroutine, since this is a blocking call.
+//
+// Watch will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (g *Group) Watch(ctx context.Context, ch chan<- Message) uint64 {
+	defer close(ch)
+
+	for {
+		v, x, ok := g.claim()
+
+		// wait for new signals on head
+		if !ok {
+			select {
+			case <-g.s.wait(x, v.seq):
+			case <-g.s.topics.done:
+				return atomic.LoadUint64(&g.s.ops)
+			case <-ctx.Done():
+				return atomic.LoadUint64(&g.s.ops)
+			}
+
+			continue
+		}
+
+		select {
+		case ch <- v.message():
+		case <-g.s.topics.done:
+			return atomic.LoadUint64(&g.s.ops)
+		case <-ctx.Done():
+			return atomic.LoadUint64(&g.s.ops)
+		}
+	}
+}
+
+// Claim advances the state of the group by one signal and returns a copy
+// of the claimed signal. It reports whether there was a signal to claim.
+// Otherwise, the current signal of the group and its copy will be returned.
+//
+// The states and the space are never locked at once. The state will only be
+// advanced, if no other member advanced it meanwhile, otherwise it is retried.
+func (g *Group) claim() (v signal, x *signal, ok bool) {
+	s := g.s
+
+	for {
+		s.states.RLock()
+		p, known := s.states.m[string(g.state)]
+		s.states.RUnlock()
+
+		s.RLock()
+
+		// no state
+		if x = p; !known {
+			x = s.root
+		}
+
+		// state was dropped, but not yet moved
+		x = s.live(x)
+
+		n := x.link()
+
+		// skip superseded signals
+		for n.superseded() {
+			n = n.link()
+		}
+
+		if ok = n != s.root; ok {
+			x = n
+		}
+
+		v = x.load()
+
+		s.RUnlock()
+
+		if !ok {
+			return
+		}
+
+		// exclusive between all members
+		s.states.Lock()
+
+		if q, k := s.states.m[string(g.state)]; q != p || k != known {
+			s.states.Unlock()
+			continue // claimed by another member
+		}
+
+		s.states.m[string(g.state)] = x
+
+		if s.journal != nil {
+			s.journal.mark(s.topic, g.state, v.seq)
+		}
+
+		s.states.Unlock()
+
+		if !known {
+			s.created(g.state)
+		}
+
+		return
+	}
+}
@@ -0,0 +1,95 @@
+package sub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("Scan should hand each signal to exactly one member", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		for i := range 100 {
+			_send(byte(i))
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+
+		seen := make(map[uint64]int)
+
+		for range 4 {
+			ch := make(chan Message)
+
+			wg.Add(1)
+
+			go s.Group(_foo).Scan(context.Background(), ch)
+
+			go func() {
+				defer wg.Done()
+
+				for m := range ch {
+					mu.Lock()
+					seen[m.Offset]++
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if len(seen) != 100 {
+			t.Fatal("Signals were not handed")
+		}
+
+		for _, n := range seen {
+			if n != 1 {
+				t.Fatal("Signal was handed twice")
+			}
+		}
+	})
+
+	t.Run("Scan should continue the group", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		s.Group(_foo).Scan(context.Background(), make(chan Message, 1))
+
+		_send(2)
+
+		ch := make(chan Message, 2)
+
+		s.Group(_foo).Scan(context.Background(), ch)
+
+		if len(ch) != 1 || (<-ch).Data[0] != 2 {
+			t.Fatal("Group was not continued")
+		}
+	})
+
+	t.Run("Watch should hand new signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+
+		defer cancel()
+
+		ch := make(chan Message)
+
+		go s.Group(_foo).Watch(ctx, ch)
+
+		_send(1)
+
+		if m := <-ch; m.Data[0] != 1 {
+			t.Fatal("Signal was not handed")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package sub
+
+// Hooks are called on events of a space and all of its topics, e.g. for
+// auditing, metrics or secondary indexes. Nil hooks will be skipped.
+//
+// Hooks are called synchronously after an event, while the space is not
+// locked, so hooks may use the space. Hooks of concurrent events may be
+// called in any order. Restoring or closing a space will not call hooks.
+type Hooks struct {
+	// OnSend is called with every appended signal.
+	OnSend func(topic string, m Message)
+	// OnDrop is called with all signals dropped or evicted at once.
+	OnDrop func(topic string, l []Message)
+	// OnStateCreate is called with every created state.
+	OnStateCreate func(topic string, state []byte)
+	// OnStateDelete is called with every deleted or dropped state.
+	OnStateDelete func(topic string, state []byte)
+}
+
+// WithHooks sets the hooks of the space, shared by all of its topics.
+func WithHooks(h Hooks) Option {
+	return func(s *Space) {
+		s.topics.hooks = h
+	}
+}
+
+// Sent calls the send hook with the given message, if any.
+func (s *Space) sent(m Message) {
+	if h := s.topics.hooks.OnSend; h != nil {
+		h(s.topic, m)
+	}
+}
+
+// Lost calls the drop hook with the given messages, if any.
+func (s *Space) lost(l []Message) {
+	if h := s.topics.hooks.OnDrop; h != nil && len(l) > 0 {
+		h(s.topic, l)
+	}
+}
+
+// Created calls the state create hook with the given state, if any.
+func (s *Space) created(state []byte) {
+	if h := s.topics.hooks.OnStateCreate; h != nil {
+		h(s.topic, state)
+	}
+}
+
+// Deleted calls the state delete hook with the given states, if any.
+func (s *Space) deleted(states ...[]byte) {
+	if h := s.topics.hooks.OnStateDelete; h != nil {
+		for _, state := range states {
+			h(s.topic, state)
+		}
+	}
+}
+
+// Hooked reports whether the drop hook is set, so that
+// dropped signals have to be kept as messages.
+func (s *Space) hooked() bool {
+	return s.topics.hooks.OnDrop != nil
+}
@@ -0,0 +1,160 @@
+package sub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	t.Run("OnSend should be called with every signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var l []Message
+
+		s := _hooked(Hooks{OnSend: func(topic string, m Message) {
+			l = append(l, m)
+		}})
+
+		s.Send(_foo)
+		s.SendTopic("foo", _bar)
+
+		if len(l) != 2 || string(l[0].Data) != "foo" || l[1].Offset != 1 {
+			t.Fatal("Messages are not correct")
+		}
+	})
+
+	t.Run("OnDrop should be called with dropped signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var l []Message
+
+		s := _hooked(Hooks{OnDrop: func(topic string, m []Message) {
+			l = append(l, m...)
+		}})
+
+		s.Send(_foo)
+		s.Send(_bar, TTL(Infinite))
+
+		s.clock.(*ManualClock).Add(10)
+		s.Drop(1)
+
+		if len(l) != 1 || string(l[0].Data) != "foo" {
+			t.Fatal("Messages are not correct")
+		}
+	})
+
+	t.Run("OnDrop should be able to use the space", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var s *Space
+
+		s = _hooked(Hooks{OnDrop: func(topic string, m []Message) {
+			s.Topic("audit").Send(_bar)
+		}})
+
+		s.SendTopic("foo", _foo)
+
+		s.clock.(*ManualClock).Add(10)
+
+		done := make(chan struct{})
+
+		go func() {
+			s.Drop(1)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Drop is deadlocked")
+		}
+
+		if s.Topic("audit").StatCount != 1 {
+			t.Fatal("Signal was not sent")
+		}
+	})
+
+	t.Run("OnDrop should be called with evicted signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var l []Message
+
+		s := _hooked(Hooks{OnDrop: func(topic string, m []Message) {
+			l = append(l, m...)
+		}})
+
+		s.Limit(1, 0, Evict)
+
+		s.Send(_foo)
+		s.Send(_bar)
+
+		if len(l) != 1 || string(l[0].Data) != "foo" {
+			t.Fatal("Messages are not correct")
+		}
+	})
+
+	t.Run("OnStateCreate should be called with new states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var l []string
+
+		s := _hooked(Hooks{OnStateCreate: func(topic string, state []byte) {
+			l = append(l, topic+":"+string(state))
+		}})
+
+		s.Send(_foo)
+		s.SendTopic("foo", _foo)
+
+		for range s.Signals(_foo) {
+		}
+
+		for range s.Signals(_foo) {
+		}
+
+		for range s.Topic("foo").Signals(_foo) {
+		}
+
+		s.Group(_bar).Scan(context.Background(), make(chan Message, 1))
+
+		if len(l) != 3 || l[0] != ":foo" || l[1] != "foo:foo" || l[2] != ":bar" {
+			t.Fatal("States are not correct")
+		}
+	})
+
+	t.Run("OnStateDelete should be called with deleted states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var l []string
+
+		s := _hooked(Hooks{OnStateDelete: func(topic string, state []byte) {
+			l = append(l, string(state))
+		}})
+
+		s.Send(_foo)
+
+		for range s.Signals(_foo) {
+		}
+
+		for range s.Signals(_bar) {
+		}
+
+		s.Delete(_bar)
+		s.Delete(_bar)
+
+		s.clock.(*ManualClock).Add(10)
+		s.Drop(1)
+
+		if len(l) != 2 || l[0] != "bar" || l[1] != "foo" {
+			t.Fatal("States are not correct")
+		}
+	})
+}
+
+func _hooked(h Hooks) *Space {
+	s := NewSpace(WithClock(NewManualClock(_epoch)), WithHooks(h))
+
+	_s.Swap(s).Close()
+
+	return s
+}
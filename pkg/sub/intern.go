@@ -1,16 +1,92 @@
 package sub
 
 import (
-	"sync/atomic"
-	"time"
+	"encoding/binary"
 )
 
-// Clock stores the time value as milliseconds since epoch
-// with an accuracy of a microsecond.
-//
-// This time value does contain any time zone information.
-func (s *Space) clock() {
-	for t := range time.Tick(time.Microsecond) {
-		atomic.StoreInt64(&s.now, t.UnixMilli())
+// AppendBytes appends the given bytes prefixed by their length.
+func appendBytes(b []byte, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+
+	return append(b, v...)
+}
+
+// A reader decodes values from a byte slice. After the first error,
+// all further values will be zero and the error flag will be set.
+type reader struct {
+	// Remaining bytes.
+	b []byte
+	// Error flag.
+	err bool
+}
+
+// Byte decodes a single byte.
+func (r *reader) byte() (v byte) {
+	if len(r.b) < 1 {
+		r.err = true
+		return
+	}
+
+	v, r.b = r.b[0], r.b[1:]
+
+	return
+}
+
+// Uvarint decodes an unsigned varint.
+func (r *reader) uvarint() (v uint64) {
+	v, n := binary.Uvarint(r.b)
+
+	if n <= 0 {
+		r.err = true
+		return 0
+	}
+
+	r.b = r.b[n:]
+
+	return
+}
+
+// Varint decodes a signed varint.
+func (r *reader) varint() (v int64) {
+	v, n := binary.Varint(r.b)
+
+	if n <= 0 {
+		r.err = true
+		return 0
+	}
+
+	r.b = r.b[n:]
+
+	return
+}
+
+// Bytes decodes bytes prefixed by their length.
+func (r *reader) bytes() (v []byte) {
+	n := r.uvarint()
+
+	if r.err || n > uint64(len(r.b)) {
+		r.err = true
+		return nil
+	}
+
+	v, r.b = r.b[:n], r.b[n:]
+
+	return
+}
+
+// Header decodes a header of key value pairs prefixed by their count.
+func (r *reader) header() (h map[string]string) {
+	n := r.uvarint()
+
+	for ; n > 0 && !r.err; n-- {
+		if h == nil {
+			h = make(map[string]string)
+		}
+
+		k, v := r.bytes(), r.bytes()
+
+		h[string(k)] = string(v)
 	}
+
+	return
 }
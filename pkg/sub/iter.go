@@ -0,0 +1,47 @@
+package sub
+
+import (
+	"iter"
+)
+
+// Signals returns an iterator over all signals since the beginning
+// or since the given state, up to the current head. States are handled
+// the same way as by Scan. The state will be saved pointing to the last
+// signal yielded, when the iteration completes or breaks.
+//
+// The space will not be locked while yielding a signal.
+func (s *Space) Signals(state []byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for _, d := range s.TimedSignals(state) {
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// TimedSignals returns an iterator over all signals the same way as
+// Signals, but yields the internal time of each signal along with its
+// data. The time is given in milliseconds since epoch.
+func (s *Space) TimedSignals(state []byte) iter.Seq2[int64, []byte] {
+	return func(yield func(int64, []byte) bool) {
+		c := s.cursor(state, true)
+
+		// save state if given
+		defer func() { s.save(state, c.x) }()
+
+		for {
+			x, v, ok := c.peek()
+
+			if !ok {
+				return
+			}
+
+			c.seek(x, v)
+
+			if !yield(v.time, v.data) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package sub
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/cuhsat/subspace/internal/pkg/sys"
+)
+
+func TestSignals(t *testing.T) {
+	t.Run("Signals should yield all signals in order", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		for i := 1; i <= 3; i++ {
+			_send(byte(i))
+		}
+
+		n := byte(0)
+
+		for d := range _s.Load().Signals(_foo) {
+			if n++; d[0] != n {
+				t.Fatal("Data is not correct")
+			}
+		}
+
+		if n != 3 {
+			t.Fatal("Count is not correct")
+		}
+	})
+
+	t.Run("Signals should stop at the head, even if it was evicted", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(3, 0, Evict)
+
+		for i := 1; i <= 3; i++ {
+			_send(byte(i))
+		}
+
+		n := 0
+
+		for range s.Signals(nil) {
+			if n++; n > 3 {
+				break
+			}
+
+			for i := 0; i < 4; i++ {
+				_send(4)
+			}
+		}
+
+		if n > 3 {
+			t.Fatal("Count is not correct")
+		}
+	})
+
+	t.Run("Signals should save the state when completed", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		for range _s.Load().Signals(_foo) {
+		}
+
+		s := _s.Load()
+
+		if s.states.m[string(_foo)] != s.head {
+			t.Fatal("State points not to head")
+		}
+	})
+
+	t.Run("Signals should save the state when broken", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		for range _s.Load().Signals(_foo) {
+			break
+		}
+
+		v := _scan(_foo)
+
+		if len(v) != 1 || v[0] != 2 {
+			t.Fatal("State is not correct")
+		}
+	})
+
+	t.Run("Signals should yield nothing when empty", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		for range _s.Load().Signals(_foo) {
+			t.Fatal("Signal was yielded")
+		}
+	})
+}
+
+func TestTimedSignals(t *testing.T) {
+	t.Run("TimedSignals should yield the signal time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		s := _s.Load()
+
+		for tm, d := range s.TimedSignals(nil) {
+			if tm != s.head.time {
+				t.Fatal("Time is not correct")
+			}
+
+			if d[0] != 1 {
+				t.Fatal("Data is not correct")
+			}
+		}
+	})
+}
+
+func BenchmarkSignals(b *testing.B) {
+	for _, m := range _tests {
+		b.Run(fmt.Sprintf("Benchmark Signals %d", m), func(b *testing.B) {
+			b.Cleanup(_cleanup)
+
+			s := _s.Load()
+			d := sys.NewBuffer()
+
+			for i := 0; i < m; i++ {
+				s.Send(d)
+			}
+
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				for range s.Signals([]byte(strconv.Itoa(n))) {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package sub
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Size after which a new journal segment will be started.
+const segmentSize = 4 << 20
+
+// Extension of journal segment files.
+const segmentExt = ".log"
+
+// A journal is an append-only write-ahead log of a space, divided into
+// segments. Every sent signal and every saved state will be written to
+// the journal as a record, while segments will be removed as soon as all
+// of their signals have been dropped. Every segment starts with the last
+// sequence number of each topic, so that offsets will never be reused.
+//
+// Records will be written to the operating system immediately, but will
+// only be synced to the disk by Sync.
+type journal struct {
+	sync.Mutex
+	// Directory of the segments.
+	dir string
+	// Active segment file, nil if not yet opened.
+	f *os.File
+	// Size of the active segment file.
+	n int
+	// All known segments, oldest first.
+	segs []*segment
+	// Index of the next segment.
+	next uint64
+	// Highest sequence number written per topic.
+	seqs map[string]uint64
+	// First error that occurred while writing.
+	err error
+	// Closed, further records will be ignored.
+	closed bool
+}
+
+// A segment is a single journal file.
+type segment struct {
+	// Path of the segment file.
+	path string
+	// Highest sequence number written per topic.
+	seqs map[string]uint64
+}
+
+// Open returns a new space, that will be made durable by a journal stored
+// in the given directory. The directory will be created if necessary. All
+// signals and states found in the journal will be restored, with their
+// original internal times, before the space is returned. Evictions and drops
+// are not journaled, but restored again by the limits of the space and by a
+// drop with its retention time, without calling any hooks.
+//
+// Any further sent signal and saved state will be written to the journal.
+// Journal segments will be removed, as soon as Drop has dropped all their
+// signals. The space will be configured by the given options.
+func Open(dir string, opts ...Option) (s *Space, err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	j := &journal{dir: dir, next: 1, seqs: make(map[string]uint64)}
+
+	s = build(opts)
+
+	if err = j.replay(s); err != nil {
+		s.Close()
+
+		return nil, err
+	}
+
+	// drop restored signals, that have been dropped before
+	h := s.topics.hooks
+
+	s.topics.hooks = Hooks{}
+	s.Drop(s.retention)
+	s.topics.hooks = h
+
+	s.topics.Lock()
+
+	for _, t := range s.topics.m {
+		t.journal = j
+	}
+
+	s.journal = j
+
+	s.topics.Unlock()
+
+	if s.interval > 0 {
+		go s.dropper()
+	}
+
+	return
+}
+
+// Sync commits the journal of the space to the disk. It returns the
+// first error that occurred while writing the journal, if any.
+//
+// Sync will do nothing for memory only spaces.
+func (s *Space) Sync() error {
+	if s.journal == nil {
+		return nil
+	}
+
+	return s.journal.sync()
+}
+
+// First returns the sequence number of the first signal of the space,
+// or of the next signal, if the space is empty.
+func (s *Space) first() uint64 {
+	s.tail.Lock()
+	defer s.tail.Unlock()
+
+	if s.root.next == s.root {
+		return s.seq + 1
+	}
+
+	return s.root.next.seq
+}
+
+// Truncate removes all journal segments, of which all signals have been
+// dropped. Topics are not locked while the segments are checked.
+func (s *Space) truncate() {
+	if s.journal == nil {
+		return
+	}
+
+	m := map[string]*Space{"": s.topics.space}
+
+	for _, t := range s.named() {
+		m[t.topic] = t
+	}
+
+	s.journal.truncate(func(topic string) uint64 {
+		if t, ok := m[topic]; ok {
+			return t.first()
+		}
+
+		return 0 // keep segments of new topics
+	}, s.stopped)
+}
+
+// Replay restores all signals and states of the journal into the given space.
+// Records after a damaged record in a segment will be ignored.
+func (j *journal) replay(s *Space) error {
+	l, err := filepath.Glob(filepath.Join(j.dir, "*"+segmentExt))
+
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(l)
+
+	ld := newLoader(s)
+
+	for _, p := range l {
+		i, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(p), segmentExt), 10, 64)
+
+		if err != nil {
+			continue // not a segment
+		}
+
+		b, err := os.ReadFile(p)
+
+		if err != nil {
+			return err
+		}
+
+		g := &segment{path: p, seqs: make(map[string]uint64)}
+
+		for len(b) > 0 {
+			r, err := record(&b)
+
+			if err != nil {
+				break // damaged record
+			}
+
+			topic, seq := ld.load(r)
+
+			g.seqs[topic] = max(g.seqs[topic], seq)
+			j.seqs[topic] = max(j.seqs[topic], seq)
+		}
+
+		j.segs, j.next = append(j.segs, g), max(j.next, i+1)
+	}
+
+	ld.done()
+
+	return nil
+}
+
+// Send writes a send record for the given signal.
+func (j *journal) send(topic string, x *signal) {
+	j.write(topic, x.seq, sendRecord(topic, x))
+}
+
+// Mark writes a mark record for the given state and sequence number.
+func (j *journal) mark(topic string, state []byte, seq uint64) {
+	j.write(topic, seq, markRecord(topic, state, seq))
+}
+
+// Unmark writes a delete record for the given state.
+func (j *journal) unmark(topic string, state []byte) {
+	j.write(topic, 0, delRecord(topic, state))
+}
+
+// Write writes the given record body to the active segment
+// and starts a new segment, if necessary.
+func (j *journal) write(topic string, seq uint64, v []byte) {
+	b := appendRecord(nil, v)
+
+	j.Lock()
+	defer j.Unlock()
+
+	if j.closed {
+		return
+	}
+
+	if j.f == nil || j.n >= segmentSize {
+		if err := j.rotate(); err != nil {
+			j.fail(err)
+			return
+		}
+	}
+
+	n, err := j.f.Write(b)
+
+	j.fail(err)
+
+	g := j.segs[len(j.segs)-1]
+
+	j.n, g.seqs[topic] = j.n+n, max(g.seqs[topic], seq)
+	j.seqs[topic] = max(j.seqs[topic], seq)
+}
+
+// Rotate closes the active segment and starts a new one,
+// beginning with the last sequence number of each topic.
+//
+// The journal must be locked.
+func (j *journal) rotate() (err error) {
+	if j.f != nil {
+		j.fail(j.f.Close())
+	}
+
+	p := filepath.Join(j.dir, fmt.Sprintf("%020d%s", j.next, segmentExt))
+
+	j.f, err = os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+
+	if err != nil {
+		j.f = nil
+		return
+	}
+
+	g := &segment{path: p, seqs: make(map[string]uint64)}
+
+	j.segs = append(j.segs, g)
+	j.next++
+	j.n = 0
+
+	for topic, seq := range j.seqs {
+		n, err := j.f.Write(appendRecord(nil, seqRecord(topic, seq)))
+
+		j.fail(err)
+
+		j.n, g.seqs[topic] = j.n+n, seq
+	}
+
+	return
+}
+
+// Truncate removes all leading segments, of which all signals have been
+// dropped. The given function must return the sequence number of the
+// first signal of a topic. The active segment will never be removed,
+// nor will any segment be removed after the journal or the given space
+// was closed, as its topics may have been emptied by then.
+func (j *journal) truncate(first func(topic string) uint64, stopped func() bool) {
+	j.Lock()
+	l := slices.Clone(j.segs[:max(len(j.segs)-1, 0)])
+	j.Unlock()
+
+	n := 0
+
+	// segments without an active one are immutable
+segs:
+	for _, g := range l {
+		for topic, seq := range g.seqs {
+			if seq >= first(topic) {
+				break segs
+			}
+		}
+
+		n++
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	if j.closed || stopped() {
+		return
+	}
+
+	for ; n > 0 && len(j.segs) > 1 && j.segs[0] == l[0]; n-- {
+		j.fail(os.Remove(l[0].path))
+
+		j.segs, l = j.segs[1:], l[1:]
+	}
+}
+
+// Sync commits the active segment to the disk and returns
+// the first error that occurred while writing.
+func (j *journal) sync() error {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.f != nil {
+		j.fail(j.f.Sync())
+	}
+
+	return j.err
+}
+
+// Close syncs and closes the active segment and returns
+// the first error that occurred while writing.
+func (j *journal) close() error {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.f != nil {
+		j.fail(j.f.Sync())
+		j.fail(j.f.Close())
+	}
+
+	j.f, j.closed = nil, true
+
+	return j.err
+}
+
+// Fail keeps the given error, if it is the first one.
+//
+// The journal must be locked.
+func (j *journal) fail(err error) {
+	if j.err == nil {
+		j.err = err
+	}
+}
@@ -0,0 +1,228 @@
+package sub
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("Open should restore signals", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+		s1.SendTopic("foo", []byte{2})
+
+		s2 := _open(t, dir)
+
+		if s2.head.data[0] != 1 || s2.head.time != s1.head.time {
+			t.Fatal("Signal was not restored")
+		}
+
+		if s2.Topic("foo").head.data[0] != 2 {
+			t.Fatal("Topic was not restored")
+		}
+
+		if s2.StatCount != 1 || s2.StatAlloc != 1 {
+			t.Fatal("Stats are not correct")
+		}
+	})
+
+	t.Run("Open should restore evictions", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir, WithLimit(2, 0, Evict))
+
+		s1.Send([]byte{1})
+		s1.Send([]byte{2})
+		s1.Send([]byte{3})
+
+		s2 := _open(t, dir, WithLimit(2, 0, Evict))
+
+		if s2.StatCount != 2 || s2.root.next.data[0] != 2 {
+			t.Fatal("Signals were not evicted")
+		}
+	})
+
+	t.Run("Open should restore drops", func(t *testing.T) {
+		dir, c := t.TempDir(), NewManualClock(_epoch)
+
+		s1 := _open(t, dir, WithClock(c), WithRetention(40))
+
+		s1.Send([]byte{1})
+		c.Add(20)
+		s1.Send([]byte{2}, TTL(10))
+		s1.Send([]byte{3})
+		c.Add(30)
+		s1.Drop(40)
+		s1.Close()
+
+		s2 := _open(t, dir, WithClock(c), WithRetention(40))
+
+		if s2.StatCount != 1 || s2.root.next.data[0] != 3 {
+			t.Fatal("Signals were not dropped")
+		}
+	})
+
+	t.Run("Open should restore states", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+		s1.Send([]byte{2})
+		s1.Scan(make(chan []byte, 2), _foo)
+		s1.Send([]byte{3})
+
+		s2 := _open(t, dir)
+
+		ch := make(chan []byte, 3)
+
+		s2.Scan(ch, _foo)
+
+		if len(ch) != 1 || (<-ch)[0] != 3 {
+			t.Fatal("State was not restored")
+		}
+	})
+
+	t.Run("Open should continue the sequence", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+
+		s2 := _open(t, dir)
+
+		s2.Send([]byte{2})
+
+		if s2.head.seq != 2 {
+			t.Fatal("Sequence was not continued")
+		}
+	})
+
+	t.Run("Open should continue the sequence of removed segments", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		for i := 0; i < 5; i++ {
+			s1.Topic("a").Send([]byte{1})
+		}
+
+		s1.Send(make([]byte, segmentSize))
+		s1.Send(make([]byte, segmentSize))
+		s1.Drop(_now)
+
+		s2 := _open(t, dir)
+
+		if q, _ := s2.Topic("a").Append([]byte{2}); q != 6 {
+			t.Fatal("Sequence was not continued")
+		}
+	})
+
+	t.Run("Open should ignore damaged records", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+
+		l, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+
+		f, err := os.OpenFile(l[0], os.O_WRONLY|os.O_APPEND, 0644)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f.Write([]byte{9, 0, 0})
+		f.Close()
+
+		s2 := _open(t, dir)
+
+		if s2.StatCount != 1 {
+			t.Fatal("Signals were not restored")
+		}
+	})
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("Drop should remove dropped segments", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := _open(t, dir)
+
+		s.Send(make([]byte, segmentSize))
+		s.Send([]byte{1})
+
+		l1, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+
+		s.Drop(_now)
+
+		l2, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+
+		if len(l1) != 2 || len(l2) != 1 {
+			t.Fatal("Segments were not removed")
+		}
+	})
+
+	t.Run("Drop should keep segments with signals", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := _open(t, dir)
+
+		s.Send(make([]byte, segmentSize))
+		s.Send([]byte{1})
+		s.Drop(Infinite)
+
+		l, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+
+		if len(l) != 2 {
+			t.Fatal("Segments were removed")
+		}
+	})
+
+	t.Run("Drop should keep segments after close", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s := _open(t, dir)
+
+		s.Send(make([]byte, segmentSize))
+		s.Send([]byte{1})
+		s.Close()
+
+		// a drop still running while closed
+		s.truncate()
+
+		l, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+
+		if len(l) != 2 {
+			t.Fatal("Segments were removed")
+		}
+	})
+
+	t.Run("Sync should succeed", func(t *testing.T) {
+		s := _open(t, t.TempDir())
+
+		s.Send([]byte{1})
+
+		if err := s.Sync(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func _open(t *testing.T, dir string, opts ...Option) *Space {
+	s, err := Open(dir, opts...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
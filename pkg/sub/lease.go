@@ -0,0 +1,76 @@
+package sub
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Lease scans a batch of signals the same way as ScanPage, but will not
+// advance the state. Instead, the batch will be leased to the state for
+// the given time in milliseconds. Following leases will continue after
+// the leased batch, until the lease expires. Continuing a lease will not
+// extend its expiry, which stays that of its oldest batch. The state will
+// only be advanced by acknowledging an offset via Ack. If the lease
+// expires before all of its signals have been acknowledged, the remaining
+// signals will be delivered again. This gives at-least-once delivery.
+//
+// Lease will return the current spaces operations count
+// as a timestamp of the spaces internal signal state and the
+// contexts error, if the scan was aborted.
+func (s *Space) Lease(ctx context.Context, ch chan<- Message, state []byte, count, size int, ttl int64, filters ...Filter) (o uint64, err error) {
+	c := s.cursor(state, true)
+
+	c.count, c.size = count, size
+
+	now := s.clock.Now()
+
+	expires := now + min(ttl, Infinite-now)
+
+	s.states.RLock()
+
+	// continue after an active lease, but keep its expiry
+	if l, ok := s.states.leases[string(state)]; ok && l.expires > now {
+		c.x, c.q, expires = l.x, l.q, l.expires
+	}
+
+	s.states.RUnlock()
+
+	x := c.x
+
+	err = scan(ctx, c, ch, (*signal).message, filters)
+
+	if state != nil && c.x != x {
+		s.states.Lock()
+		s.states.leases[string(state)] = lease{x: c.x, q: c.q, expires: expires}
+		s.states.Unlock()
+	}
+
+	close(ch)
+
+	return atomic.LoadUint64(&s.ops), err
+}
+
+// Ack acknowledges all signals up to the given offset for the given state.
+// The state will be advanced to the signal with the given offset, but will
+// never be moved backwards. If all signals of the states lease have been
+// acknowledged, the lease will be released.
+//
+// Ack will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) Ack(state []byte, offset uint64) uint64 {
+	if _, q := s.load(state); offset > q {
+		x, _ := s.at(offset + 1)
+
+		s.save(state, x)
+	}
+
+	s.states.Lock()
+
+	if l, ok := s.states.leases[string(state)]; ok && l.q <= offset {
+		delete(s.states.leases, string(state))
+	}
+
+	s.states.Unlock()
+
+	return atomic.LoadUint64(&s.ops)
+}
@@ -0,0 +1,132 @@
+package sub
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLease(t *testing.T) {
+	t.Run("Lease should redeliver after expiry", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		_lease(0, -1)
+
+		if v := _lease(0, -1); len(v) != 2 || v[0] != 1 {
+			t.Fatal("Signals were not redelivered")
+		}
+	})
+
+	t.Run("Lease should continue after an active lease", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		_lease(1, Infinite)
+
+		if v := _lease(1, Infinite); len(v) != 1 || v[0] != 2 {
+			t.Fatal("Lease was not continued")
+		}
+	})
+
+	t.Run("Lease should keep the expiry of a continued lease", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		_lease(1, 100)
+
+		_tick(50)
+
+		_lease(1, 100)
+
+		_tick(60)
+
+		if v := _lease(1, 100); len(v) != 1 || v[0] != 1 {
+			t.Fatal("Signals were not redelivered")
+		}
+	})
+
+	t.Run("Lease should not advance the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		_lease(0, Infinite)
+
+		if _, ok := _s.Load().Lag(_foo); ok {
+			t.Fatal("State was advanced")
+		}
+	})
+}
+
+func TestAck(t *testing.T) {
+	t.Run("Ack should advance the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+		_send(3)
+
+		_lease(2, Infinite)
+
+		_s.Load().Ack(_foo, 2)
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not advanced")
+		}
+	})
+
+	t.Run("Ack should release the lease", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		_lease(2, Infinite)
+
+		_s.Load().Ack(_foo, 1)
+
+		if _, ok := _s.Load().states.leases[string(_foo)]; !ok {
+			t.Fatal("Lease was released")
+		}
+
+		_s.Load().Ack(_foo, 2)
+
+		if _, ok := _s.Load().states.leases[string(_foo)]; ok {
+			t.Fatal("Lease was not released")
+		}
+	})
+
+	t.Run("Ack should not move the state backwards", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		s := _s.Load()
+
+		s.Ack(_foo, 2)
+		s.Ack(_foo, 1)
+
+		if n, _ := s.Lag(_foo); n != 0 {
+			t.Fatal("State was moved backwards")
+		}
+	})
+}
+
+func _lease(count int, ttl int64) (bs []byte) {
+	ch := make(chan Message, 3)
+
+	_s.Load().Lease(context.Background(), ch, _foo, count, 0, ttl)
+
+	for m := range ch {
+		bs = append(bs, m.Data[0])
+	}
+
+	return
+}
@@ -0,0 +1,287 @@
+package sub
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// A Policy decides how a space behaves, if it is full.
+type Policy int
+
+const (
+	// Evict will drop the oldest signals until the sent signal fits.
+	Evict Policy = iota
+	// Reject will reject the sent signal with ErrFull.
+	Reject
+	// Block will block the sender until the signal fits.
+	Block
+)
+
+// ErrFull is returned for signals rejected because the space is full.
+var ErrFull = errors.New("space full")
+
+// A limit restricts the signals of a space.
+type limit struct {
+	// Maximum count of signals, zero for no maximum.
+	count uint64
+	// Maximum allocated memory, zero for no maximum.
+	size uint64
+	// Policy if the maximum is reached.
+	policy Policy
+}
+
+// Limit restricts the space to the given maximum count of signals and
+// the given maximum allocated memory (in bytes). A maximum of zero will
+// not be restricted. If a sent signal would exceed a maximum, the given
+// policy decides how the space behaves.
+//
+// If called on the default topic, all other topics will be limited too,
+// including all topics created afterwards.
+//
+// Blocked senders will only continue after signals have been dropped.
+// A signal larger than the maximum allocated memory or a batch exceeding
+// a maximum on its own will always be rejected with ErrFull.
+func (s *Space) Limit(count, size uint64, p Policy) {
+	l := limit{count, size, p}
+
+	s.restrict(l)
+
+	if s.topic == "" {
+		s.topics.RLock()
+
+		for _, t := range s.topics.m {
+			t.restrict(l)
+		}
+
+		s.topics.RUnlock()
+	}
+}
+
+// Restrict sets the limit of the space and wakes blocked senders.
+func (s *Space) restrict(l limit) {
+	s.tail.Lock()
+	s.limit = l
+	s.release()
+	s.tail.Unlock()
+}
+
+// Fit makes room for the given count of signals with the given total
+// size according to the limit policy. It returns the evicted signals as
+// messages, if dropped signals are hooked, and ErrFull, if the signals
+// were rejected.
+//
+// The tail must be locked.
+func (s *Space) fit(c, n int) (l []Message, err error) {
+	for s.full(c, n) {
+		if s.limit.size > 0 && uint64(n) > s.limit.size {
+			return l, ErrFull
+		}
+
+		if s.limit.count > 0 && uint64(c) > s.limit.count {
+			return l, ErrFull
+		}
+
+		if t := s.topics.total; (t.size > 0 && uint64(n) > t.size) || (t.count > 0 && uint64(c) > t.count) {
+			return l, ErrFull
+		}
+
+		switch s.limit.policy {
+		case Evict:
+			// evict the oldest signal of all topics, if only the total is exceeded
+			if !s.over(c, n) {
+				s.tail.Unlock()
+
+				v, ev := s.topics.evict()
+
+				if v != nil {
+					v.lost(ev)
+				}
+
+				s.tail.Lock()
+
+				if s.stopped() {
+					return l, ErrClosed
+				}
+
+				if v == nil {
+					return l, ErrFull
+				}
+
+				continue
+			}
+
+			if s.root.next == s.root {
+				return l, ErrFull
+			}
+
+			s.Lock()
+			l = s.keep(l, s.root.next)
+			s.evict()
+			s.Unlock()
+
+		case Block:
+			w, v := s.await(), s.topics.await()
+
+			s.tail.Unlock()
+
+			select {
+			case <-w:
+			case <-v:
+			}
+
+			s.tail.Lock()
+
+			if s.stopped() {
+				return l, ErrClosed
+			}
+
+		default:
+			return l, ErrFull
+		}
+	}
+
+	return
+}
+
+// Full reports whether the given count of signals with the given total
+// size would exceed the limit or the total limit of all topics.
+//
+// The tail must be locked.
+func (s *Space) full(c, n int) bool {
+	return s.over(c, n) || s.topics.over(c, n)
+}
+
+// Over reports whether the given count of signals with the given total
+// size would exceed the limit of the space itself.
+//
+// The tail must be locked.
+func (s *Space) over(c, n int) bool {
+	l := &s.limit
+
+	if l.count > 0 && atomic.LoadUint64(&s.StatCount)+uint64(c) > l.count {
+		return true
+	}
+
+	return l.size > 0 && atomic.LoadUint64(&s.StatAlloc)+uint64(n) > l.size
+}
+
+// Over reports whether the given count of signals with the given total
+// size would exceed the total limit of all topics.
+func (t *topics) over(c, n int) bool {
+	l := &t.total
+
+	if l.count > 0 && atomic.LoadUint64(&t.count)+uint64(c) > l.count {
+		return true
+	}
+
+	return l.size > 0 && atomic.LoadUint64(&t.alloc)+uint64(n) > l.size
+}
+
+// Account adds the given count of signals and allocated memory to the
+// stats of the space and to the totals of all topics.
+func (s *Space) account(c, n int) {
+	atomic.AddUint64(&s.StatCount, uint64(c))
+	atomic.AddUint64(&s.StatAlloc, uint64(n))
+	atomic.AddUint64(&s.topics.count, uint64(c))
+	atomic.AddUint64(&s.topics.alloc, uint64(n))
+}
+
+// Evict invalidates the oldest signal of the space. States may still
+// point to the signal until the next call to Drop.
+//
+// The space and its tail must be locked.
+func (s *Space) evict() {
+	x := s.root.next
+
+	if s.root.next = x.next; x == s.head {
+		s.head = s.root
+	}
+
+	s.free(x)
+
+	atomic.AddUint64(&s.ops, 1)
+}
+
+// Evict invalidates the oldest signal of all topics. It returns the topic
+// of the signal and the signal as message, if dropped signals are hooked,
+// or nil, if all topics are empty.
+//
+// No tail may be locked.
+func (t *topics) evict() (v *Space, l []Message) {
+	var last int64
+
+	for _, s := range append(t.space.named(), t.space) {
+		s.RLock()
+
+		if x := s.root.link(); x != s.root && (v == nil || x.time < last) {
+			v, last = s, x.time
+		}
+
+		s.RUnlock()
+	}
+
+	if v == nil {
+		return
+	}
+
+	v.tail.Lock()
+	v.Lock()
+
+	// the signal may have been dropped meanwhile
+	if v.root.next != v.root {
+		l = v.keep(l, v.root.next)
+		v.evict()
+	}
+
+	v.Unlock()
+	v.tail.Unlock()
+
+	return
+}
+
+// Await returns a channel that will be closed with the next drop.
+//
+// The tail must be locked.
+func (s *Space) await() <-chan struct{} {
+	if s.room == nil {
+		s.room = make(chan struct{})
+	}
+
+	return s.room
+}
+
+// Release wakes all blocked senders.
+//
+// The tail must be locked.
+func (s *Space) release() {
+	if s.room != nil {
+		close(s.room)
+
+		s.room = nil
+	}
+}
+
+// Await returns a channel that will be closed with the next drop
+// of any topic.
+func (t *topics) await() <-chan struct{} {
+	t.wait.Lock()
+	defer t.wait.Unlock()
+
+	if t.room == nil {
+		t.room = make(chan struct{})
+	}
+
+	return t.room
+}
+
+// Release wakes all senders blocked by the total limit.
+func (t *topics) release() {
+	t.wait.Lock()
+	defer t.wait.Unlock()
+
+	if t.room != nil {
+		close(t.room)
+
+		t.room = nil
+	}
+}
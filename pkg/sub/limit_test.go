@@ -0,0 +1,171 @@
+package sub
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimit(t *testing.T) {
+	t.Run("Limit should evict by count", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(2, 0, Evict)
+
+		for i := 1; i <= 3; i++ {
+			_send(byte(i))
+		}
+
+		if v := _scan(nil); len(v) != 2 || v[0] != 2 {
+			t.Fatal("Signal was not evicted")
+		}
+
+		if s.StatCount != 2 || s.StatAlloc != 2 {
+			t.Fatal("Stats are not correct")
+		}
+	})
+
+	t.Run("Limit should evict by size", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(0, 4, Evict)
+
+		s.Send(_foo)
+		s.Send(_bar)
+
+		if v := _scan(nil); len(v) != 1 || v[0] != 'b' {
+			t.Fatal("Signal was not evicted")
+		}
+	})
+
+	t.Run("Limit should evict the head", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, Evict)
+
+		_send(1)
+		_send(2)
+
+		if s.root.next != s.head || s.head.data[0] != 2 {
+			t.Fatal("Head is not correct")
+		}
+	})
+
+	t.Run("Limit should release evicted signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, Evict)
+
+		s.Send(_foo, Headers(map[string]string{"a": "1"}))
+
+		x := s.head
+
+		s.Send(_bar)
+
+		if x.data != nil || x.header != nil || x.next != s.root {
+			t.Fatal("Signal was not released")
+		}
+	})
+
+	t.Run("Limit should reset evicted states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_s.Load().Limit(2, 0, Evict)
+
+		_send(1)
+		_scan(_foo)
+		_send(2)
+		_send(3)
+
+		if v := _scan(_foo); len(v) != 2 || v[0] != 2 {
+			t.Fatal("State was not reset")
+		}
+	})
+
+	t.Run("Limit should reject", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, Reject)
+
+		o1 := _send(1)
+		o2, err := s.TrySend([]byte{2})
+
+		if err != ErrFull {
+			t.Fatal("Signal was not rejected")
+		}
+
+		if o1 != o2 {
+			t.Fatal("Offset was changed")
+		}
+
+		if s.head.data[0] != 1 {
+			t.Fatal("Signal was appended")
+		}
+	})
+
+	t.Run("Limit should reject too large signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(0, 2, Evict)
+
+		if _, err := s.TrySend(_foo); err != ErrFull {
+			t.Fatal("Signal was not rejected")
+		}
+	})
+
+	t.Run("Limit should block", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(1, 0, Block)
+
+		_send(1)
+
+		done := make(chan struct{})
+
+		go func() { _send(2); close(done) }()
+
+		select {
+		case <-done:
+			t.Fatal("Sender was not blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		_drop()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Sender was not released")
+		}
+	})
+
+	t.Run("Limit should limit all topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Topic("foo")
+		s.Limit(1, 0, Reject)
+
+		for _, k := range []string{"foo", "bar"} {
+			s.SendTopic(k, _foo)
+
+			if _, err := s.Topic(k).TrySend(_bar); err != ErrFull {
+				t.Fatal("Topic is not limited")
+			}
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package sub
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Append will append the given signal the same way as TrySend, but will
+// return the offset of the signal instead of the operations count.
+//
+// Offsets start with one and increase by one with every signal sent to
+// a topic. They are never reused, not even after the signal was dropped,
+// and are restored together with the signals from a journal or snapshot.
+func (s *Space) Append(data []byte, attrs ...Attr) (uint64, error) {
+	_, q, err := s.send(data, attrs)
+
+	return q, err
+}
+
+// ScanMessages scans all signals the same way as ScanContext, but writes
+// each signal as a message with its offset, time and header to the given
+// channel.
+//
+// ScanMessages will return the current spaces operations count
+// as a timestamp of the spaces internal signal state and the
+// contexts error, if the scan was aborted.
+func (s *Space) ScanMessages(ctx context.Context, ch chan<- Message, state []byte, filters ...Filter) (o uint64, err error) {
+	return s.ScanPage(ctx, ch, state, 0, 0, filters...)
+}
+
+// ScanPage scans all signals the same way as ScanMessages, but will stop
+// the scan before the given maximum count of signals or the given maximum
+// size of their data would be exceeded. A zero stands for no limit. At least
+// one signal will always be written. The state will only be advanced to the
+// last signal written to the channel, so that a backlog can be paged through
+// by scanning again with the same state.
+//
+// ScanPage will return the current spaces operations count
+// as a timestamp of the spaces internal signal state and the
+// contexts error, if the scan was aborted.
+func (s *Space) ScanPage(ctx context.Context, ch chan<- Message, state []byte, count, size int, filters ...Filter) (o uint64, err error) {
+	c := s.cursor(state, true)
+
+	c.count, c.size = count, size
+
+	err = scan(ctx, c, ch, (*signal).message, filters)
+
+	// save state if given
+	s.save(state, c.x)
+
+	close(ch)
+
+	return atomic.LoadUint64(&s.ops), err
+}
+
+// Lookup returns the signal with the given offset as a message.
+// It reports whether the signal exists and was not dropped yet.
+func (s *Space) Lookup(offset uint64) (m Message, ok bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for x := s.root.link(); x != s.root && x.seq <= offset; x = x.link() {
+		if x.seq == offset {
+			return x.message(), true
+		}
+	}
+
+	return
+}
+
+// ScanFrom scans all signals with the given offset or a higher one.
+// The given channel will be closed. No state will be used or saved.
+//
+// This should be run as a goroutine or a big enough channel must
+// be provided, since this is a blocking call.
+//
+// ScanFrom will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) ScanFrom(ch chan<- []byte, offset uint64) uint64 {
+	c := s.cursor(nil, true)
+
+	c.x, c.q = s.at(offset)
+
+	scan(context.Background(), c, ch, (*signal).value, nil)
+
+	close(ch)
+
+	return atomic.LoadUint64(&s.ops)
+}
+
+// SeekOffset positions the given state at the signal with the given offset
+// or the next higher one. The next scan using the state will start with this
+// signal. If the state does not exist, it will be created.
+//
+// SeekOffset will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) SeekOffset(state []byte, offset uint64) uint64 {
+	x, _ := s.at(offset)
+
+	s.save(state, x)
+
+	return atomic.LoadUint64(&s.ops)
+}
+
+// At returns the last signal with an offset lower than the given one
+// and its sequence number. If there is no such signal, the root will
+// be returned.
+func (s *Space) at(offset uint64) (x *signal, q uint64) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for x = s.root; x.link() != s.root && x.link().seq < offset; x = x.link() {
+	}
+
+	return x, x.seq
+}
+
+// Message returns the signal as a message.
+func (x *signal) message() Message {
+	return Message{Offset: x.seq, Time: x.time, Header: x.header, Key: x.key, Data: x.data}
+}
@@ -0,0 +1,159 @@
+package sub
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	t.Run("Append should return increasing offsets", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		o1, _ := s.Append(_foo)
+		s.Drop(0)
+		o2, _ := s.Append(_bar)
+
+		if o1 != 1 || o2 != 2 {
+			t.Fatal("Offsets are not correct")
+		}
+	})
+}
+
+func TestScanMessages(t *testing.T) {
+	t.Run("ScanMessages should deliver offsets with data", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		ch := make(chan Message, 2)
+
+		_s.Load().ScanMessages(context.Background(), ch, _foo)
+
+		m1, m2 := <-ch, <-ch
+
+		if m1.Offset != 1 || m1.Data[0] != 1 || m2.Offset != 2 || m2.Data[0] != 2 {
+			t.Fatal("Messages are not correct")
+		}
+
+		if v := _scan(_foo); len(v) != 0 {
+			t.Fatal("State was not saved")
+		}
+	})
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("Lookup should return the signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		if m, ok := _s.Load().Lookup(2); !ok || m.Data[0] != 2 {
+			t.Fatal("Signal is not correct")
+		}
+	})
+
+	t.Run("Lookup should report dropped signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		s := _s.Load()
+
+		s.Drop(-1)
+
+		if _, ok := s.Lookup(1); ok {
+			t.Fatal("Signal was found")
+		}
+	})
+}
+
+func TestScanFrom(t *testing.T) {
+	t.Run("ScanFrom should return signals from the offset", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+		_send(3)
+
+		ch := make(chan []byte, 3)
+
+		_s.Load().ScanFrom(ch, 2)
+
+		if len(ch) != 2 || (<-ch)[0] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+}
+
+func TestSeekOffset(t *testing.T) {
+	t.Run("SeekOffset should position the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+		_send(3)
+
+		_scan(_foo)
+
+		_s.Load().SeekOffset(_foo, 3)
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not positioned")
+		}
+	})
+}
+
+func TestScanPage(t *testing.T) {
+	t.Run("ScanPage should stop at the count", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+		_send(3)
+
+		if v := _scanPage(2, 0); len(v) != 2 || v[1] != 2 {
+			t.Fatal("Page is not correct")
+		}
+
+		if v := _scanPage(2, 0); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not advanced to the page")
+		}
+	})
+
+	t.Run("ScanPage should stop at the size", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		if v := _scanPage(0, 1); len(v) != 1 || v[0] != 1 {
+			t.Fatal("Page is not correct")
+		}
+	})
+
+	t.Run("ScanPage should return at least one signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_s.Load().Send([]byte("foo"))
+
+		if v := _scanPage(0, 1); len(v) != 1 {
+			t.Fatal("Page is empty")
+		}
+	})
+}
+
+func _scanPage(count, size int) (bs []byte) {
+	ch := make(chan Message, 3)
+
+	_s.Load().ScanPage(context.Background(), ch, _foo, count, size)
+
+	for m := range ch {
+		bs = append(bs, m.Data[0])
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package sub
+
+import (
+	"time"
+)
+
+// An Option configures a space on creation.
+type Option func(*Space)
+
+// WithClock sets the clock of the space, instead of its internal clock.
+// The clock will be shared by all topics of the space.
+func WithClock(c Clock) Option {
+	return func(s *Space) {
+		s.clock = c
+	}
+}
+
+// WithRetention sets the retention time in milliseconds, used by the
+// automatic drops of the space. By default, signals will only be dropped
+// after their own time to live.
+func WithRetention(retention int64) Option {
+	return func(s *Space) {
+		s.retention = retention
+	}
+}
+
+// WithAutoDrop drops the signals of the space with its retention time
+// in the given interval, until the space is closed.
+func WithAutoDrop(interval time.Duration) Option {
+	return func(s *Space) {
+		s.interval = interval
+	}
+}
+
+// WithLimit restricts the space and all of its topics the same way as Limit.
+func WithLimit(count, size uint64, p Policy) Option {
+	return func(s *Space) {
+		s.Limit(count, size, p)
+	}
+}
+
+// WithTotal restricts all topics of the space together to the given
+// maximum count of signals and the given maximum allocated memory (in
+// bytes). A maximum of zero will not be restricted. If a sent signal
+// would exceed a maximum, the policy of its topic decides. If evicted,
+// the oldest signals of all topics will be evicted first.
+func WithTotal(count, size uint64) Option {
+	return func(s *Space) {
+		s.topics.total = limit{count: count, size: size}
+	}
+}
+
+// Dropper drops the signals of the space with its retention time
+// in its interval, until the space is closed.
+func (s *Space) dropper() {
+	t := time.NewTicker(s.interval)
+
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			s.Drop(s.retention)
+		case <-s.topics.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sub
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithAutoDrop(t *testing.T) {
+	t.Run("WithAutoDrop should drop with the retention time", func(t *testing.T) {
+		c := NewManualClock(_epoch)
+
+		s := NewSpace(WithClock(c), WithRetention(1000), WithAutoDrop(time.Millisecond))
+
+		defer s.Close()
+
+		s.Send(_foo)
+
+		c.Add(1001)
+
+		for i := 0; atomic.LoadUint64(&s.StatCount) > 0; i++ {
+			if i > 1000 {
+				t.Fatal("Signal was not dropped")
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("WithAutoDrop should keep signals without a retention time", func(t *testing.T) {
+		c := NewManualClock(_epoch)
+
+		s := NewSpace(WithClock(c), WithAutoDrop(time.Millisecond))
+
+		defer s.Close()
+
+		s.Send(_foo)
+
+		c.Add(1001)
+
+		time.Sleep(10 * time.Millisecond)
+
+		if atomic.LoadUint64(&s.StatCount) != 1 {
+			t.Fatal("Signal was dropped")
+		}
+	})
+}
+
+func TestWithLimit(t *testing.T) {
+	t.Run("WithLimit should limit the space", func(t *testing.T) {
+		s := NewSpace(WithLimit(1, 0, Reject))
+
+		defer s.Close()
+
+		s.Send(_foo)
+
+		if _, err := s.TrySend(_bar); err != ErrFull {
+			t.Fatal("Signal was not rejected")
+		}
+
+		if s.Topic("foo").limit != s.limit {
+			t.Fatal("Topic was not limited")
+		}
+	})
+}
+
+func TestWithTotal(t *testing.T) {
+	t.Run("WithTotal should limit all topics together", func(t *testing.T) {
+		s := NewSpace(WithTotal(2, 0), WithLimit(0, 0, Reject))
+
+		defer s.Close()
+
+		s.Send(_foo)
+		s.SendTopic("foo", _foo)
+
+		if _, err := s.Topic("bar").TrySend(_bar); err != ErrFull {
+			t.Fatal("Signal was not rejected")
+		}
+	})
+
+	t.Run("WithTotal should evict the oldest signals of all topics", func(t *testing.T) {
+		c := NewManualClock(_epoch)
+		s := NewSpace(WithClock(c), WithTotal(0, 6))
+
+		defer s.Close()
+
+		s.Send(_foo)
+		c.Add(1)
+		s.SendTopic("foo", _foo)
+		c.Add(1)
+		s.SendTopic("foo", _bar)
+
+		if s.StatCount != 0 || s.Topic("foo").StatCount != 2 || s.topics.alloc != 6 {
+			t.Fatal("Signal was not evicted")
+		}
+
+		if _, err := s.Topic("bar").TrySend(_bar); err != nil {
+			t.Fatal("Signal was rejected")
+		}
+
+		if x := s.Topic("foo").root.next; string(x.data) != "bar" {
+			t.Fatal("Signal was not evicted")
+		}
+	})
+
+	t.Run("WithTotal should not starve other topics", func(t *testing.T) {
+		s := NewSpace(WithTotal(3, 0))
+
+		defer s.Close()
+
+		for range 3 {
+			s.SendTopic("foo", _foo)
+		}
+
+		if _, err := s.Topic("bar").TrySend(_bar); err != nil {
+			t.Fatal("Signal was rejected")
+		}
+
+		if s.Topic("foo").StatCount != 2 || s.Topic("bar").StatCount != 1 {
+			t.Fatal("Signal was not evicted")
+		}
+	})
+
+	t.Run("WithTotal should block until any topic drops", func(t *testing.T) {
+		s := NewSpace(WithClock(NewManualClock(_epoch)), WithTotal(1, 0), WithLimit(0, 0, Block))
+
+		defer s.Close()
+
+		s.SendTopic("foo", _foo)
+
+		done := make(chan struct{})
+
+		go func() { s.Send(_bar); close(done) }()
+
+		select {
+		case <-done:
+			t.Fatal("Sender was not blocked")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		s.DropTopic("foo", -1)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Sender was not released")
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package sub
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"maps"
+	"slices"
+)
+
+// A record is the binary representation of a space altering event, as used by
+// journals and snapshots. Each record consists of the length of its body as an
+// unsigned varint, the CRC-32 checksum of its body and the body itself. A body
+// starts with the records kind, followed by the topic and a sequence number.
+//
+// Record kinds.
+const (
+	recordSend byte = 's' // a signal was sent.
+	recordMark byte = 'm' // a state was saved.
+	recordDel  byte = 'd' // a state was deleted.
+	recordSeq  byte = 'q' // the last sequence number of a topic.
+)
+
+// Error for records that can not be read.
+var errRecord = errors.New("invalid record")
+
+// SendRecord returns the body of a send record for the given signal.
+func sendRecord(topic string, x *signal) []byte {
+	b := []byte{recordSend}
+
+	b = appendBytes(b, []byte(topic))
+	b = binary.AppendUvarint(b, x.seq)
+	b = binary.AppendVarint(b, x.time)
+	b = appendBytes(b, x.data)
+	b = binary.AppendVarint(b, x.ttl)
+	b = binary.AppendUvarint(b, uint64(len(x.header)))
+
+	for _, k := range slices.Sorted(maps.Keys(x.header)) {
+		b = appendBytes(b, []byte(k))
+		b = appendBytes(b, []byte(x.header[k]))
+	}
+
+	b = appendBytes(b, []byte(x.key))
+	b = appendBytes(b, []byte(x.id))
+
+	return b
+}
+
+// MarkRecord returns the body of a mark record for the given state.
+func markRecord(topic string, state []byte, seq uint64) []byte {
+	b := []byte{recordMark}
+
+	b = appendBytes(b, []byte(topic))
+	b = binary.AppendUvarint(b, seq)
+	b = appendBytes(b, state)
+
+	return b
+}
+
+// DelRecord returns the body of a delete record for the given state.
+func delRecord(topic string, state []byte) []byte {
+	b := []byte{recordDel}
+
+	b = appendBytes(b, []byte(topic))
+	b = binary.AppendUvarint(b, 0)
+	b = appendBytes(b, state)
+
+	return b
+}
+
+// SeqRecord returns the body of a sequence record for the given topic,
+// so that its offsets will never be reused, even if all of its send
+// records are gone.
+func seqRecord(topic string, seq uint64) []byte {
+	b := []byte{recordSeq}
+
+	b = appendBytes(b, []byte(topic))
+	b = binary.AppendUvarint(b, seq)
+
+	return b
+}
+
+// AppendRecord appends the given body as a record.
+func appendRecord(b []byte, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	b = binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(v))
+
+	return append(b, v...)
+}
+
+// Record reads the body of the next record from the given bytes and
+// advances them.
+func record(b *[]byte) (r *reader, err error) {
+	n, l := binary.Uvarint(*b)
+
+	if l <= 0 || len(*b) < l+4 || n > uint64(len(*b)-l-4) {
+		return nil, errRecord
+	}
+
+	c := binary.LittleEndian.Uint32((*b)[l:])
+	v := (*b)[l+4 : l+4+int(n)]
+
+	if crc32.ChecksumIEEE(v) != c {
+		return nil, errRecord
+	}
+
+	*b = (*b)[l+4+int(n):]
+
+	return &reader{b: v}, nil
+}
+
+// A loader restores signals and states from records into a space.
+// States will only be restored, after all records have been loaded.
+type loader struct {
+	// Restored space.
+	s *Space
+	// Last sequence number per state and topic.
+	marks map[*Space]map[string]uint64
+}
+
+// NewLoader returns a new loader for the given space.
+func newLoader(s *Space) *loader {
+	return &loader{s: s, marks: make(map[*Space]map[string]uint64)}
+}
+
+// Load restores the given record body and returns its topic and sequence number.
+func (ld *loader) load(r *reader) (topic string, seq uint64) {
+	k, topic, seq := r.byte(), string(r.bytes()), r.uvarint()
+
+	t := ld.s.Topic(topic)
+
+	switch k {
+	case recordSend:
+		v := signal{seq: seq, time: r.varint(), data: bytes.Clone(r.bytes()), ttl: r.varint()}
+
+		// records without a header, key or id are valid
+		if len(r.b) > 0 {
+			v.header = r.header()
+		}
+
+		if len(r.b) > 0 {
+			v.key = string(r.bytes())
+		}
+
+		if len(r.b) > 0 {
+			v.id = string(r.bytes())
+		}
+
+		if !r.err {
+			t.restore(v)
+		}
+
+	case recordMark:
+		state := r.bytes()
+
+		if !r.err {
+			if ld.marks[t] == nil {
+				ld.marks[t] = make(map[string]uint64)
+			}
+
+			ld.marks[t][string(state)] = seq
+		}
+
+	case recordDel:
+		state := r.bytes()
+
+		if !r.err {
+			delete(ld.marks[t], string(state))
+		}
+
+	case recordSeq:
+		t.tail.Lock()
+		t.seq = max(t.seq, seq)
+		t.tail.Unlock()
+	}
+
+	return
+}
+
+// Done points all loaded states to their restored signals.
+// States pointing to unknown signals will be ignored.
+func (ld *loader) done() {
+	for t, m := range ld.marks {
+		idx := make(map[uint64][]string)
+
+		for k, seq := range m {
+			idx[seq] = append(idx[seq], k)
+		}
+
+		t.states.Lock()
+
+		for x := t.root; ; {
+			for _, k := range idx[x.seq] {
+				t.states.m[k] = x
+			}
+
+			if x = x.next; x == t.root {
+				break
+			}
+		}
+
+		t.states.Unlock()
+	}
+}
+
+// Restore appends a copy of the given signal with its sequence number
+// and time at the end of the space, without writing it to the journal.
+// If the space is full, the oldest signals will be evicted regardless
+// of its limit policy, as they have been evicted before.
+func (s *Space) restore(v signal) {
+	x := &v
+
+	x.next = s.root
+
+	s.tail.Lock()
+
+	for s.full(1, len(x.data)) {
+		// evict the oldest signal of all topics, if only the total is exceeded
+		if !s.over(1, len(x.data)) {
+			s.tail.Unlock()
+
+			t, _ := s.topics.evict()
+
+			s.tail.Lock()
+
+			if t == nil {
+				break
+			}
+
+			continue
+		}
+
+		if s.root.next == s.root {
+			break
+		}
+
+		s.Lock()
+		s.evict()
+		s.Unlock()
+	}
+
+	s.index(x)
+	s.remember(x)
+	s.head.chain(x)
+	s.head = x
+	s.seq = max(s.seq, x.seq)
+
+	if x.ttl != 0 {
+		s.ttls++
+	}
+
+	s.account(1, len(x.data))
+
+	s.tail.Unlock()
+}
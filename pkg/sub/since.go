@@ -0,0 +1,54 @@
+package sub
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ScanSince scans all signals received at or after the given time,
+// given in milliseconds since epoch. The given channel will be closed.
+// No state will be used or saved.
+//
+// This should be run as a goroutine or a big enough channel must
+// be provided, since this is a blocking call.
+//
+// ScanSince will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) ScanSince(ch chan<- []byte, t int64) uint64 {
+	c := s.cursor(nil, true)
+
+	c.x, c.q = s.before(t)
+
+	scan(context.Background(), c, ch, (*signal).value, nil)
+
+	close(ch)
+
+	return atomic.LoadUint64(&s.ops)
+}
+
+// Seek positions the given state at the first signal received at or after
+// the given time, given in milliseconds since epoch. The next scan using
+// the state will start with this signal. States can be moved backwards
+// and forwards this way. If the state does not exist, it will be created.
+//
+// Seek will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) Seek(state []byte, t int64) uint64 {
+	x, _ := s.before(t)
+
+	s.save(state, x)
+
+	return atomic.LoadUint64(&s.ops)
+}
+
+// Before returns the last signal received before the given time and its
+// sequence number. If there is no such signal, the root will be returned.
+func (s *Space) before(t int64) (x *signal, q uint64) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for x = s.root; x.link() != s.root && x.link().time < t; x = x.link() {
+	}
+
+	return x, x.seq
+}
@@ -0,0 +1,102 @@
+package sub
+
+import (
+	"testing"
+)
+
+func TestScanSince(t *testing.T) {
+	t.Run("ScanSince should return signals since the time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100, 200, 300)
+
+		if v := _scanSince(200); len(v) != 2 || v[0] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("ScanSince should return everything for old times", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100, 200, 300)
+
+		if v := _scanSince(0); len(v) != 3 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("ScanSince should return nothing for new times", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100, 200, 300)
+
+		if v := _scanSince(400); len(v) != 0 {
+			t.Fatal("Data is not empty")
+		}
+	})
+}
+
+func TestSeek(t *testing.T) {
+	t.Run("Seek should rewind the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100, 200, 300)
+
+		_scan(_foo)
+
+		_s.Load().Seek(_foo, 200)
+
+		if v := _scan(_foo); len(v) != 2 || v[0] != 2 {
+			t.Fatal("State was not rewound")
+		}
+	})
+
+	t.Run("Seek should forward the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100, 200, 300)
+
+		_s.Load().Seek(_foo, 250)
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 3 {
+			t.Fatal("State was not forwarded")
+		}
+	})
+
+	t.Run("Seek should create the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_times(100)
+
+		s := _s.Load()
+
+		s.Seek(_foo, 200)
+
+		if s.states.m[string(_foo)] != s.head {
+			t.Fatal("State was not created")
+		}
+	})
+}
+
+func _times(ts ...int64) {
+	s := _s.Load()
+
+	for i, t := range ts {
+		_send(byte(i + 1))
+
+		s.head.time = t
+	}
+}
+
+func _scanSince(t int64) []byte {
+	bs := make([]byte, 0)
+	ch := make(chan []byte)
+
+	go _s.Load().ScanSince(ch, t)
+
+	for v := range ch {
+		bs = append(bs, v[0])
+	}
+
+	return bs
+}
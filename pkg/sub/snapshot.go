@@ -0,0 +1,129 @@
+package sub
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Header of a snapshot.
+const snapshotMagic = "subspace\x00\x01"
+
+var (
+	// ErrSnapshot is returned for snapshots that can not be read.
+	ErrSnapshot = errors.New("invalid snapshot")
+	// ErrNotEmpty is returned for restoring into a space with signals.
+	ErrNotEmpty = errors.New("space not empty")
+)
+
+// Snapshot writes a point-in-time copy of all signals, with their internal
+// times, and all states and last offsets of all topics to the given writer.
+// Each topic will be copied consistently, while it is locked for reading.
+//
+// The space will not be locked while writing.
+func (s *Space) Snapshot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(snapshotMagic)
+
+	for _, name := range append([]string{""}, s.Topics()...) {
+		t := s.Topic(name)
+
+		var l []signal
+
+		m := make(map[string]uint64)
+
+		t.RLock()
+
+		for x := t.root.link(); x != t.root; x = x.link() {
+			l = append(l, x.load())
+		}
+
+		t.states.RLock()
+
+		// skip states, that will be dropped
+		for k, x := range t.states.m {
+			if y := t.live(x); y == x || y != t.root {
+				m[k] = y.seq
+			}
+		}
+
+		t.states.RUnlock()
+
+		t.RUnlock()
+
+		// at least the last copied signal
+		t.tail.Lock()
+		seq := t.seq
+		t.tail.Unlock()
+
+		bw.Write(appendRecord(nil, seqRecord(name, seq)))
+
+		for i := range l {
+			bw.Write(appendRecord(nil, sendRecord(name, &l[i])))
+		}
+
+		for k, seq := range m {
+			bw.Write(appendRecord(nil, markRecord(name, []byte(k), seq)))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Restore reads a snapshot from the given reader and restores all of its
+// signals, with their internal times, and all of its states. The space
+// must not contain any signals. Restored signals and states will also be
+// written to the journal of a space, if any.
+//
+// If the snapshot is damaged, all signals up to the damage will be restored.
+func (s *Space) Restore(r io.Reader) error {
+	if s.stopped() {
+		return ErrClosed
+	}
+
+	for _, name := range append([]string{""}, s.Topics()...) {
+		t := s.Topic(name)
+
+		t.tail.Lock()
+		ok := t.head == t.root
+		t.tail.Unlock()
+
+		if !ok {
+			return ErrNotEmpty
+		}
+	}
+
+	b, err := io.ReadAll(r)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(b, []byte(snapshotMagic)) {
+		return ErrSnapshot
+	}
+
+	b = b[len(snapshotMagic):]
+
+	ld := newLoader(s)
+
+	defer ld.done()
+
+	for len(b) > 0 {
+		r, err := record(&b)
+
+		if err != nil {
+			return ErrSnapshot
+		}
+
+		v := r.b
+
+		if topic, seq := ld.load(r); s.journal != nil {
+			s.journal.write(topic, seq, v)
+		}
+	}
+
+	return nil
+}
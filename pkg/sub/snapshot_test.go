@@ -0,0 +1,171 @@
+package sub
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("Snapshot should write all signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_send(1)
+		_send(2)
+
+		if err := _s.Load().Snapshot(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.HasPrefix(b.Bytes(), []byte(snapshotMagic)) {
+			t.Fatal("Header is not correct")
+		}
+
+		if b.Len() <= len(snapshotMagic) {
+			t.Fatal("Signals were not written")
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("Restore should restore signals and states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		s1 := _s.Load()
+
+		_send(1)
+		_scan(_foo)
+		_send(2)
+
+		s1.SendTopic("foo", []byte{3})
+		s1.Snapshot(&b)
+
+		h := *s1.head
+
+		_cleanup()
+
+		s2 := _s.Load()
+
+		if err := s2.Restore(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if s2.head.time != h.time || s2.head.seq != h.seq {
+			t.Fatal("Signal was not restored")
+		}
+
+		if v := _scan(_foo); len(v) != 1 || v[0] != 2 {
+			t.Fatal("State was not restored")
+		}
+
+		if v := _scanTopic("foo", nil); len(v) != 1 || v[0] != 3 {
+			t.Fatal("Topic was not restored")
+		}
+
+		if s2.StatCount != 2 || s2.StatAlloc != 2 {
+			t.Fatal("Stats are not correct")
+		}
+	})
+
+	t.Run("Restore should write the journal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_send(1)
+		_scan(_foo)
+
+		_s.Load().Snapshot(&b)
+
+		dir := t.TempDir()
+
+		if err := _open(t, dir).Restore(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		s := _open(t, dir)
+
+		if s.StatCount != 1 || s.states.m[string(_foo)] != s.head {
+			t.Fatal("Journal was not written")
+		}
+	})
+
+	t.Run("Restore should fail if not empty", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_s.Load().Snapshot(&b)
+
+		_send(1)
+
+		if _s.Load().Restore(&b) != ErrNotEmpty {
+			t.Fatal("Space was not empty")
+		}
+	})
+
+	t.Run("Restore should keep producer ids", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_dedup(60000).Send(_foo, ID("x"))
+		_s.Load().Snapshot(&b)
+
+		s := _dedup(60000)
+
+		if err := s.Restore(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.TrySend(_bar, ID("x")); !errors.Is(err, ErrDuplicate) {
+			t.Fatal("Producer id was not restored")
+		}
+	})
+
+	t.Run("Restore should continue the sequence", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_send(1)
+		_send(2)
+		_drop()
+
+		_s.Load().Snapshot(&b)
+
+		_cleanup()
+
+		_s.Load().Restore(&b)
+
+		if q, _ := _s.Load().Append(_foo); q != 3 {
+			t.Fatal("Sequence was not continued")
+		}
+	})
+
+	t.Run("Restore should fail if damaged", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		var b bytes.Buffer
+
+		_send(1)
+
+		_s.Load().Snapshot(&b)
+
+		_cleanup()
+
+		d := b.Bytes()
+
+		if _s.Load().Restore(bytes.NewReader(d[:len(d)-1])) != ErrSnapshot {
+			t.Fatal("Snapshot was not damaged")
+		}
+
+		if _s.Load().Restore(bytes.NewReader(_foo)) != ErrSnapshot {
+			t.Fatal("Snapshot was not damaged")
+		}
+	})
+}
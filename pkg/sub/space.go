@@ -1,66 +1,240 @@
 package sub
 
 import (
+	"context"
 	"math"
 	"runtime"
-	"sync"
 	"sync/atomic"
+	"unsafe"
 )
 
 // Infinite retention time
 const Infinite = math.MaxInt64
 
-// NewSpace returns a new Space struct with its fields initialized.
-//
-// Each space contains its own pool for signal structures.
+// NewSpace returns a new Space struct with its fields initialized
+// and configured by the given options.
 //
 // NewSpace will only return if the spaces internal clock is running.
 // So a call has minimum duration time of one tenth of a millisecond.
-func NewSpace() (s *Space) {
-	s = &Space{
-		states: &states{m: make(map[string]*signal)},
-		root:   &signal{Infinite, nil, nil},
-		pool: sync.Pool{
-			New: func() any {
-				return &signal{next: s.root}
-			},
-		},
+// The clock will run until the space is closed. If a clock is given
+// via WithClock, the internal clock will not be started.
+func NewSpace(opts ...Option) (s *Space) {
+	s = build(opts)
+
+	if s.interval > 0 {
+		go s.dropper()
 	}
 
-	// initialize internal clock
-	go s.clock()
+	return
+}
 
-	// form the space to a circle
-	s.head, s.root.next = s.root, s.root
+// Build returns a new space configured by the given options,
+// with its clock running, but without automatic drops.
+func build(opts []Option) (s *Space) {
+	s = newSpace(nil, &topics{m: make(map[string]*Space), done: make(chan struct{})}, "")
 
-	// wait till internal clock is running
-	for atomic.LoadInt64(&s.now) == 0 {
-		runtime.Gosched()
+	s.topics.space, s.retention = s, Infinite
+
+	for _, o := range opts {
+		o(s)
 	}
 
+	if s.clock == nil {
+		t := &ticker{}
+
+		s.clock = t
+
+		// initialize internal clock
+		go t.run(s.topics.done)
+
+		// wait till internal clock is running
+		for t.Now() == 0 {
+			runtime.Gosched()
+		}
+	}
+
+	return
+}
+
+// NewSpace returns a new space formed to a circle,
+// using the given clock and topics storage.
+func newSpace(c Clock, t *topics, topic string) (s *Space) {
+	s = &Space{
+		clock:  c,
+		states: &states{m: make(map[string]*signal), leases: make(map[string]lease)},
+		topics: t,
+		topic:  topic,
+		root:   &signal{time: Infinite},
+	}
+
+	// form the space to a circle
+	s.head, s.root.next = s.root, s.root
+
 	return
 }
 
 // Send will append the given signal at the end of the space.
+// The signal can be sent with additional attributes. If the space
+// is full and its limit policy is Reject, the signal is discarded.
 //
-// While the signal is appended, the space will be locked.
+// While the signal is appended, other senders will be blocked,
+// but the space will not be locked for reading.
 //
 // Send will return the current spaces operations count
 // as a timestamp of the spaces internal signal state.
-func (s *Space) Send(data []byte) uint64 {
-	x := s.pool.Get().(*signal)
+func (s *Space) Send(data []byte, attrs ...Attr) uint64 {
+	o, _ := s.TrySend(data, attrs...)
 
-	x.time, x.data = atomic.LoadInt64(&s.now), data
+	return o
+}
 
-	// lock for fast append
-	s.Lock()
-	s.head.next, s.head = x, x
-	s.Unlock()
+// TrySend will append the given signal the same way as Send, but will
+// return ErrFull, if the signal was rejected because the space is full,
+// ErrDuplicate, if it was already sent within the dedup window,
+// or ErrClosed, if the space was closed.
+//
+// TrySend will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) TrySend(data []byte, attrs ...Attr) (uint64, error) {
+	o, _, err := s.send(data, attrs)
+
+	return o, err
+}
+
+// Send appends the given signal with the given attributes and returns
+// the spaces operations count and the offset of the appended signal.
+func (s *Space) send(data []byte, attrs []Attr) (o, q uint64, err error) {
+	return s.push([]*signal{newSignal(data, s.root, attrs)}, len(data))
+}
+
+// SendBatch will append all given signals at once at the end of the space,
+// each with the given attributes. Either all or none of the signals will be
+// appended, the same way as by TrySend. Readers will never see a part of the
+// batch, since all of its signals will be published at once.
+//
+// SendBatch will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) SendBatch(data [][]byte, attrs ...Attr) (uint64, error) {
+	if len(data) == 0 {
+		return atomic.LoadUint64(&s.ops), nil
+	}
+
+	l, n := make([]*signal, len(data)), 0
+
+	for i, d := range data {
+		l[i], n = newSignal(d, s.root, attrs), n+len(d)
+	}
+
+	o, _, err := s.push(l, n)
+
+	return o, err
+}
+
+// NewSignal returns a new signal with the given data and attributes,
+// pointing to the given root.
+func newSignal(data []byte, root *signal, attrs []Attr) *signal {
+	x := &signal{data: data, next: root}
+
+	for _, a := range attrs {
+		a(x)
+	}
+
+	return x
+}
+
+// Push appends the given signals with the given total size at once and
+// returns the spaces operations count and the offset of the last signal.
+func (s *Space) push(l []*signal, n int) (o, q uint64, err error) {
+	// lock only the tail for fast append
+	s.tail.Lock()
+
+	if s.stopped() {
+		s.tail.Unlock()
+
+		return atomic.LoadUint64(&s.ops), 0, ErrClosed
+	}
+
+	// skip already seen signals if deduplicated
+	if l, n, q, err = s.unique(l, n); err != nil {
+		s.tail.Unlock()
+
+		return atomic.LoadUint64(&s.ops), q, err
+	}
+
+	// make room if limited
+	ev, err := s.fit(len(l), n)
+
+	if err != nil {
+		s.tail.Unlock()
+		s.lost(ev)
+
+		return atomic.LoadUint64(&s.ops), 0, err
+	}
+
+	// skip signals seen while the tail was unlocked
+	if s.limit.policy != Reject {
+		if l, _, q, err = s.unique(l, n); err != nil {
+			s.tail.Unlock()
+
+			return atomic.LoadUint64(&s.ops), q, err
+		}
+	}
+
+	var ms []Message
+
+	p, now := s.head, s.clock.Now()
+
+	for i, x := range l {
+		if i > 0 {
+			l[i-1].next = x // not yet published
+		}
+
+		s.add(x, now)
+
+		if s.topics.hooks.OnSend != nil {
+			ms = append(ms, x.message())
+		}
+	}
+
+	// publish all signals to readers at once
+	p.chain(l[0])
+
+	s.wakeup()
+
+	q = s.seq
+
+	s.tail.Unlock()
+
+	s.lost(ev)
 
-	atomic.AddUint64(&s.StatAlloc, uint64(len(data)))
-	atomic.AddUint64(&s.StatCount, 1)
+	for _, m := range ms {
+		s.sent(m)
+	}
 
-	return atomic.AddUint64(&s.ops, 1)
+	return atomic.AddUint64(&s.ops, 1), q, nil
+}
+
+// Add appends the given signal with the given time at the end of the
+// space, but does not publish it to readers.
+//
+// The tail must be locked.
+func (s *Space) add(x *signal, now int64) {
+	s.seq++
+	x.time, x.seq = now, s.seq
+	s.index(x)
+	s.remember(x)
+
+	s.head = x
+
+	if x.ttl != 0 {
+		s.ttls++
+	}
+
+	if s.journal != nil {
+		s.journal.send(s.topic, x)
+	}
+
+	s.account(1, len(x.data))
 }
 
 // Scan all signals since the beginning or since the given state.
@@ -77,45 +251,34 @@ func (s *Space) Send(data []byte) uint64 {
 // Scan will return the current spaces operations count
 // as a timestamp of the spaces internal signal state.
 func (s *Space) Scan(ch chan<- []byte, state []byte) uint64 {
-	k := state
+	o, _ := s.ScanContext(context.Background(), ch, state)
 
-	// fork state if prefixed
-	if len(state) > 0 && state[0] == '!' {
-		k = state[1:]
-	}
-
-	s.states.RLock()
-	x, ok := s.states.m[string(k)]
-	s.states.RUnlock()
-
-	s.RLock()
-
-	// skip if already on head
-	if x != s.head {
+	return o
+}
 
-		// no state or state was dropped
-		if !ok || x.data == nil {
-			x = s.root
-		}
+// ScanContext scans all signals the same way as Scan, but will abort
+// the scan if the given context is done or the space is closed before
+// all signals have been written to the channel. The state will then
+// point to the last signal written to the channel. If filters are given,
+// only the signals selected by all of them will be written to the channel.
+//
+// The space will not be locked while waiting for the channel.
+//
+// ScanContext will return the current spaces operations count
+// as a timestamp of the spaces internal signal state and the
+// contexts error, if the scan was aborted, or ErrClosed,
+// if the space was closed.
+func (s *Space) ScanContext(ctx context.Context, ch chan<- []byte, state []byte, filters ...Filter) (o uint64, err error) {
+	c := s.cursor(state, true)
 
-		// iterate through all signals until head
-		for x = x.next; x != s.root; x = x.next {
-			ch <- x.data
-		}
-	}
+	err = scan(ctx, c, ch, (*signal).value, filters)
 
 	// save state if given
-	if state != nil {
-		s.states.Lock()
-		s.states.m[string(state)] = s.head
-		s.states.Unlock()
-	}
-
-	s.RUnlock()
+	s.save(state, c.x)
 
 	close(ch)
 
-	return atomic.LoadUint64(&s.ops)
+	return atomic.LoadUint64(&s.ops), err
 }
 
 // Drop invalidates all signals older than the given retention time.
@@ -123,28 +286,56 @@ func (s *Space) Scan(ch chan<- []byte, state []byte) uint64 {
 // so that the garbage collector will remove it afterwards.
 //
 // Drop will also remove all states that point to an invalid signal.
+// If called on the default topic, all other topics will be dropped too.
 //
-// While the signals are invalidated, the space will be locked.
+// While the signals are invalidated, the space will be locked
+// and all senders will be blocked.
 //
 // Drop will return the current spaces operations count
 // as a timestamp of the spaces internal signal state.
 func (s *Space) Drop(retention int64) uint64 {
-	t, o := atomic.LoadInt64(&s.now)-retention, uint64(0)
+	if s.stopped() {
+		return atomic.LoadUint64(&s.ops)
+	}
 
-	s.RLock()
-	x := s.root.next
-	s.RUnlock()
+	// topics are not locked while dropping, as hooks may use them
+	if s.topic == "" {
+		for _, t := range s.named() {
+			t.drop(retention)
+		}
+	}
+
+	o := s.drop(retention)
 
+	s.truncate()
+
+	return o
+}
+
+// Drop invalidates all signals of the space older than the given retention time,
+// older than their own time to live or superseded by a newer signal.
+func (s *Space) drop(retention int64) uint64 {
+	now, o := s.clock.Now(), uint64(0)
+
+	var moved map[*signal]*signal
+	var l []Message
+	var d [][]byte
+
+	s.tail.Lock()
 	s.Lock()
 
+	n := s.dropped
+
+	x := s.root.next
+
 	// invalidate all signals until new enough
-	for n := x; t > x.time; x, o = n, 1 {
-		atomic.AddUint64(&s.StatAlloc, ^uint64(len(x.data)-1))
-		atomic.AddUint64(&s.StatCount, ^uint64(0))
+	for x.expired(now, retention) {
+		n := x.next
 
-		n, x.data, x.next = x.next, nil, s.root
+		l = s.keep(l, x)
+		s.free(x)
 
-		s.pool.Put(x)
+		x, o = n, 1
 	}
 
 	// reset head if invalid
@@ -154,18 +345,206 @@ func (s *Space) Drop(retention int64) uint64 {
 
 	s.root.next = x
 
+	// invalidate all signals behind, that lived too long or were superseded
+	if s.ttls > 0 || s.stale > 0 {
+		moved = make(map[*signal]*signal)
+
+		for p := s.root; p.next != s.root; {
+			if x = p.next; x.stale == 0 && !x.expired(now, retention) {
+				p = x
+				continue
+			}
+
+			if p.next, o = x.next, 1; x == s.head {
+				s.head = p
+			}
+
+			moved[x] = p
+
+			l = s.keep(l, x)
+			s.free(x)
+		}
+	}
+
+	// wake blocked senders
+	if o > 0 {
+		s.release()
+		s.topics.release()
+	}
+
+	if s.window > 0 {
+		s.expire(now)
+	}
+
+	s.rated(n, now)
+
 	s.Unlock()
+	s.tail.Unlock()
 
 	s.states.Lock()
 
 	// drop now invalid states
 	for k, v := range s.states.m {
-		if v.data == nil {
+		if p, ok := moved[v]; ok {
+			s.states.m[k] = p
+		} else if v.data == nil {
 			delete(s.states.m, k)
+
+			d = append(d, []byte(k))
+		}
+	}
+
+	// drop expired leases
+	for k, l := range s.states.leases {
+		if l.expires <= now {
+			delete(s.states.leases, k)
 		}
 	}
 
 	s.states.Unlock()
 
+	s.lost(l)
+	s.deleted(d...)
+
 	return atomic.AddUint64(&s.ops, o)
 }
+
+// Free invalidates the given signal. The signal will never be reused,
+// as readers may still hold it, until it was collected.
+//
+// The space and its tail must be locked.
+func (s *Space) free(x *signal) {
+	s.account(-1, -len(x.data))
+
+	if x.ttl != 0 {
+		s.ttls--
+	}
+
+	s.unindex(x)
+	s.forget(x)
+
+	s.dropped++
+
+	x.data, x.header, x.next = nil, nil, s.root
+}
+
+// Keep appends the given signal as a message to the given
+// messages, if dropped signals are hooked.
+func (s *Space) keep(l []Message, x *signal) []Message {
+	if s.hooked() {
+		l = append(l, x.message())
+	}
+
+	return l
+}
+
+// Load returns the signal the given state points to and its sequence number.
+// If the state does not exist, the root signal will be returned. If its
+// signal was dropped, the last signal before it will be returned, or the
+// root signal, if there is none. If a state begins with an '!', the signal
+// of the state without the exclamation mark will be returned.
+func (s *Space) load(state []byte) (x *signal, q uint64) {
+	k := state
+
+	// fork state if prefixed
+	if len(state) > 0 && state[0] == '!' {
+		k = state[1:]
+	}
+
+	s.states.RLock()
+	x, ok := s.states.m[string(k)]
+	s.states.RUnlock()
+
+	s.RLock()
+	defer s.RUnlock()
+
+	// no state
+	if !ok {
+		x = s.root
+	}
+
+	// state was dropped, but not yet moved
+	x = s.live(x)
+
+	return x, x.seq
+}
+
+// Save points the given state to the given signal, if a state is given
+// and the space was not closed.
+func (s *Space) save(state []byte, x *signal) {
+	if state != nil && !s.stopped() {
+		s.states.Lock()
+		_, ok := s.states.m[string(state)]
+		s.states.m[string(state)] = x
+		s.states.Unlock()
+
+		if !ok {
+			s.created(state)
+		}
+
+		if s.journal != nil {
+			s.RLock()
+			q := x.seq
+			s.RUnlock()
+
+			s.journal.mark(s.topic, state, q)
+		}
+	}
+}
+
+// Next returns the signal following the given signal, which had the given
+// sequence number when it was scanned. If the given signal was dropped
+// meanwhile, the first signal with a higher sequence number will be
+// returned. If the given signal is the head, the root signal will be returned.
+//
+// The space must be locked for reading.
+func (s *Space) next(x *signal, q uint64) *signal {
+	if x != s.root && (x.data == nil || x.seq != q) {
+		// skip all signals already passed
+		for x = s.root; x.link() != s.root && x.link().seq <= q; x = x.link() {
+		}
+	}
+
+	return x.link()
+}
+
+// Live returns the given signal or, if it was dropped meanwhile, the last
+// signal with a lower sequence number. If there is no such signal, the
+// root signal will be returned.
+//
+// The space must be locked for reading.
+func (s *Space) live(x *signal) *signal {
+	if x == s.root || x.data != nil {
+		return x
+	}
+
+	q := x.seq
+
+	// skip all signals before
+	for x = s.root; x.link() != s.root && x.link().seq < q; x = x.link() {
+	}
+
+	return x
+}
+
+// Link returns the next signal. Safe while signals are appended.
+func (x *signal) link() *signal {
+	return (*signal)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&x.next))))
+}
+
+// Chain sets the next signal. Safe while the space is locked for reading.
+func (x *signal) chain(n *signal) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&x.next)), unsafe.Pointer(n))
+}
+
+// Superseded reports whether the signal was superseded by a newer signal.
+// Safe while signals are appended.
+func (x *signal) superseded() bool {
+	return atomic.LoadUint32(&x.stale) != 0
+}
+
+// Load returns a copy of the signal without its links.
+// Safe while signals are appended.
+func (x *signal) load() signal {
+	return signal{time: x.time, seq: x.seq, ttl: x.ttl, header: x.header, key: x.key, id: x.id, data: x.data}
+}
@@ -1,8 +1,10 @@
 package sub
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -14,6 +16,8 @@ import (
 
 const (
 	_now = -1 // immediately
+
+	_epoch = 1e12 // manual clock start
 )
 
 var (
@@ -30,14 +34,12 @@ var _s atomic.Pointer[Space]
 func Example() {
 	s := NewSpace()
 
+	defer s.Close()
+
 	s.Send([]byte("hello"))
 	s.Send([]byte("world"))
 
-	ch := make(chan []byte)
-
-	go s.Scan(ch, nil)
-
-	for x := range ch {
+	for x := range s.Signals(nil) {
 		fmt.Println(string(x))
 	}
 
@@ -64,6 +66,8 @@ func TestNewSpace(t *testing.T) {
 			t.Fatal("Space is nil")
 		}
 
+		defer s.Close()
+
 		if s.StatCount != 0 {
 			t.Fatal("Count is not zero")
 		}
@@ -154,6 +158,82 @@ func TestSend(t *testing.T) {
 			t.Fatal("Memory is not correct")
 		}
 	})
+
+	t.Run("Send should not wait for readers", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.RLock()
+		_send(1)
+		s.RUnlock()
+
+		if s.head.data[0] != 1 {
+			t.Fatal("Signal data is not correct")
+		}
+	})
+}
+
+func TestSendBatch(t *testing.T) {
+	t.Run("SendBatch should append all signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		o1 := atomic.LoadUint64(&s.ops)
+		o2, err := s.SendBatch([][]byte{{1}, {2}, {3}}, TTL(Infinite))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if o2 != o1+1 {
+			t.Fatal("Offset is not correct")
+		}
+
+		if v := _scan(nil); len(v) != 3 || v[0] != 1 || v[2] != 3 {
+			t.Fatal("Signals are not correct")
+		}
+
+		if s.head.seq != 3 || s.head.ttl != Infinite || s.ttls != 3 {
+			t.Fatal("Signal is not correct")
+		}
+	})
+
+	t.Run("SendBatch should reject all signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(2, 0, Reject)
+
+		_send(1)
+
+		if _, err := s.SendBatch([][]byte{{2}, {3}}); err != ErrFull {
+			t.Fatal("Batch was not rejected")
+		}
+
+		if v := _scan(nil); len(v) != 1 {
+			t.Fatal("Signals were appended")
+		}
+	})
+
+	t.Run("SendBatch should evict for all signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Limit(2, 0, Evict)
+
+		_send(1)
+		_send(2)
+
+		s.SendBatch([][]byte{{3}, {4}})
+
+		if v := _scan(nil); len(v) != 2 || v[0] != 3 {
+			t.Fatal("Signals were not evicted")
+		}
+	})
 }
 
 func TestScan(t *testing.T) {
@@ -359,6 +439,71 @@ func TestScan(t *testing.T) {
 	})
 }
 
+func TestScanContext(t *testing.T) {
+	t.Run("ScanContext should abort if cancelled", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cancel()
+
+		_, err := _s.Load().ScanContext(ctx, make(chan []byte), _foo)
+
+		if err != context.Canceled {
+			t.Fatal("Scan was not aborted")
+		}
+
+		if len(_scan(_foo)) != 1 {
+			t.Fatal("State was moved")
+		}
+	})
+
+	t.Run("ScanContext should save the last written signal", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+
+		defer cancel()
+
+		_s.Load().ScanContext(ctx, make(chan []byte, 1), _foo)
+
+		v := _scan(_foo)
+
+		if len(v) != 1 || v[0] != 2 {
+			t.Fatal("State is not correct")
+		}
+	})
+
+	t.Run("ScanContext should not lock while waiting", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		defer cancel()
+
+		go _s.Load().ScanContext(ctx, make(chan []byte), _foo)
+
+		time.Sleep(10 * time.Millisecond)
+
+		done := make(chan struct{})
+
+		go func() { _send(2); _drop(); close(done) }()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Space is locked")
+		}
+	})
+}
+
 func TestDrop(t *testing.T) {
 	t.Run("Drop should change offset", func(t *testing.T) {
 		t.Cleanup(_cleanup)
@@ -419,12 +564,35 @@ func TestDrop(t *testing.T) {
 		}
 	})
 
+	t.Run("Drop should keep signals within the retention time", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+		_tick(1000)
+
+		s.Drop(1000)
+
+		if s.head == s.root {
+			t.Fatal("Signal was dropped")
+		}
+
+		_tick(1)
+
+		s.Drop(1000)
+
+		if s.head != s.root {
+			t.Fatal("Signal was not dropped")
+		}
+	})
+
 	t.Run("Drop should reset head", func(t *testing.T) {
 		t.Cleanup(_cleanup)
 
 		_send(1)
 
-		time.Sleep(time.Second)
+		_tick(1000)
 
 		_drop()
 
@@ -448,7 +616,7 @@ func TestDrop(t *testing.T) {
 
 		_send(1)
 
-		time.Sleep(time.Second)
+		_tick(1000)
 
 		_scan(_foo)
 		_drop()
@@ -490,7 +658,7 @@ func TestDrop(t *testing.T) {
 			_send(byte(i))
 		}
 
-		time.Sleep(time.Second)
+		_tick(1000)
 
 		var wg sync.WaitGroup
 
@@ -523,7 +691,7 @@ func BenchmarkNewSpace(b *testing.B) {
 		b.Cleanup(_cleanup)
 
 		for n := 0; n < b.N; n++ {
-			NewSpace()
+			NewSpace().Close()
 		}
 	})
 }
@@ -592,6 +760,128 @@ func BenchmarkDrop(b *testing.B) {
 	}
 }
 
+func BenchmarkMixed(b *testing.B) {
+	for _, m := range _tests[:3] {
+		// sends and scans holding the whole space as reference, like before the tail lock
+		for _, locked := range []bool{false, true} {
+			b.Run(fmt.Sprintf("Benchmark Mixed %d Locked %t", m, locked), func(b *testing.B) {
+				b.Cleanup(_cleanup)
+
+				s := _s.Load()
+				d := sys.NewBuffer()
+
+				ctx, cancel := context.WithCancel(context.Background())
+
+				var wg sync.WaitGroup
+
+				// concurrent scanners and dropper
+				for i := 0; i < m; i++ {
+					wg.Add(1)
+
+					go func() {
+						defer wg.Done()
+						_scanLoop(ctx, s, fmt.Appendf(nil, "%d", i), locked)
+					}()
+				}
+
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+					_dropLoop(ctx, s)
+				}()
+
+				b.ResetTimer()
+
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						if locked {
+							_lockedSend(s, d)
+						} else {
+							s.Send(d)
+						}
+					}
+				})
+
+				b.StopTimer()
+
+				// stop all loops before the space is closed
+				cancel()
+				wg.Wait()
+			})
+		}
+	}
+}
+
+func _scanLoop(ctx context.Context, s *Space, state []byte, locked bool) {
+	for ctx.Err() == nil {
+		ch := make(chan []byte, 64)
+
+		if locked {
+			go _lockedScan(ctx, s, ch, state)
+		} else {
+			go s.ScanContext(ctx, ch, state)
+		}
+
+		for range ch {
+		}
+
+		runtime.Gosched()
+	}
+}
+
+// _lockedScan writes all signals up to the head the same way as ScanContext,
+// but holds the space locked for reading during the whole scan, including
+// waiting for the channel, as every scan did before.
+func _lockedScan(ctx context.Context, s *Space, ch chan<- []byte, state []byte) {
+	defer close(ch)
+
+	x, q := s.load(state)
+
+	s.RLock()
+
+loop:
+	for h := s.head; x != h; {
+		n := s.next(x, q)
+
+		select {
+		case ch <- n.data:
+			x, q = n, n.seq
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	s.RUnlock()
+
+	s.save(state, x)
+}
+
+// _lockedSend appends the given data under the whole space lock, as
+// every send did before, but without going through push.
+func _lockedSend(s *Space, d []byte) {
+	s.tail.Lock()
+	defer s.tail.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	p, x := s.head, newSignal(d, s.root, nil)
+
+	s.add(x, s.clock.Now())
+
+	p.chain(x)
+}
+
+func _dropLoop(ctx context.Context, s *Space) {
+	for ctx.Err() == nil {
+		s.clock.(*ManualClock).Add(1)
+		s.Drop(10)
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func _send(b byte) uint64 {
 	return _s.Load().Send([]byte{b})
 }
@@ -613,6 +903,12 @@ func _drop() uint64 {
 	return _s.Load().Drop(_now)
 }
 
+func _tick(d int64) {
+	_s.Load().clock.(*ManualClock).Add(d)
+}
+
 func _cleanup() {
-	_s.Swap(NewSpace())
+	if s := _s.Swap(NewSpace(WithClock(NewManualClock(_epoch)))); s != nil {
+		s.Close()
+	}
 }
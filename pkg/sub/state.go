@@ -0,0 +1,106 @@
+package sub
+
+import (
+	"bytes"
+	"slices"
+)
+
+// States returns the names of all states of the space in sorted order.
+func (s *Space) States() []string {
+	s.states.RLock()
+	defer s.states.RUnlock()
+
+	l := make([]string, 0, len(s.states.m))
+
+	for k := range s.states.m {
+		l = append(l, k)
+	}
+
+	slices.Sort(l)
+
+	return l
+}
+
+// Lag returns the count of signals the given state is behind the head of
+// the space, without superseded signals. It reports whether the state
+// exists. The exclamation mark prefix has no special meaning here.
+func (s *Space) Lag(state []byte) (n uint64, ok bool) {
+	s.states.RLock()
+	x, ok := s.states.m[string(state)]
+	s.states.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	// state was dropped, but not yet moved
+	x = s.live(x)
+
+	for ; x.link() != s.root; x = x.link() {
+		if !x.link().superseded() {
+			n++
+		}
+	}
+
+	return
+}
+
+// Delete removes the given state. It reports whether the state existed.
+// The next scan using the state will start from the beginning.
+func (s *Space) Delete(state []byte) bool {
+	s.states.Lock()
+	_, ok := s.states.m[string(state)]
+	delete(s.states.m, string(state))
+	delete(s.states.leases, string(state))
+	s.states.Unlock()
+
+	if ok && s.journal != nil {
+		s.journal.unmark(s.topic, state)
+	}
+
+	if ok {
+		s.deleted(state)
+	}
+
+	return ok
+}
+
+// Copy saves the given source state under the given destination name,
+// replacing any existing destination state. It reports whether the source
+// state existed. This is the same as forking a state by its prefix. An
+// empty destination name will be rejected.
+func (s *Space) Copy(src, dst []byte) bool {
+	if len(dst) == 0 {
+		return false
+	}
+
+	s.states.RLock()
+	x, ok := s.states.m[string(src)]
+	s.states.RUnlock()
+
+	if ok {
+		s.save(dst, x)
+	}
+
+	return ok
+}
+
+// Rename moves the given source state to the given destination name,
+// replacing any existing destination state. It reports whether the
+// source state existed. An empty destination name will be rejected,
+// same as by Copy.
+func (s *Space) Rename(src, dst []byte) bool {
+	if len(dst) == 0 {
+		return false
+	}
+
+	if bytes.Equal(src, dst) {
+		_, ok := s.Lag(src)
+		return ok
+	}
+
+	return s.Copy(src, dst) && s.Delete(src)
+}
@@ -0,0 +1,158 @@
+package sub
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStates(t *testing.T) {
+	t.Run("States should return all states sorted", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+		_scan(_bar)
+
+		if l := _s.Load().States(); !slices.Equal(l, []string{"bar", "foo"}) {
+			t.Fatal("States are not correct")
+		}
+	})
+}
+
+func TestLag(t *testing.T) {
+	t.Run("Lag should return the signals behind the head", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+		_send(2)
+		_send(3)
+
+		if n, ok := _s.Load().Lag(_foo); !ok || n != 2 {
+			t.Fatal("Lag is not correct")
+		}
+	})
+
+	t.Run("Lag should report unknown states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if _, ok := _s.Load().Lag(_foo); ok {
+			t.Fatal("State does exist")
+		}
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("Delete should remove the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+
+		if !_s.Load().Delete(_foo) {
+			t.Fatal("State was not found")
+		}
+
+		if v := _scan(_foo); len(v) != 1 {
+			t.Fatal("State was not removed")
+		}
+	})
+
+	t.Run("Delete should be journaled", func(t *testing.T) {
+		dir := t.TempDir()
+
+		s1 := _open(t, dir)
+
+		s1.Send([]byte{1})
+		s1.Scan(make(chan []byte, 1), _foo)
+		s1.Delete(_foo)
+
+		if s2 := _open(t, dir); len(s2.States()) != 0 {
+			t.Fatal("State was restored")
+		}
+	})
+}
+
+func TestCopy(t *testing.T) {
+	t.Run("Copy should duplicate the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+		_send(2)
+
+		if !_s.Load().Copy(_foo, _bar) {
+			t.Fatal("State was not found")
+		}
+
+		if v := _scan(_bar); len(v) != 1 || v[0] != 2 {
+			t.Fatal("State was not copied")
+		}
+
+		if n, _ := _s.Load().Lag(_foo); n != 1 {
+			t.Fatal("State was altered")
+		}
+	})
+
+	t.Run("Copy should fail for unknown states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		if _s.Load().Copy(_foo, _bar) {
+			t.Fatal("State was copied")
+		}
+	})
+
+	t.Run("Copy should fail for empty targets", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+
+		if _s.Load().Copy(_foo, nil) || _s.Load().Copy(_foo, []byte{}) {
+			t.Fatal("State was copied")
+		}
+	})
+}
+
+func TestRename(t *testing.T) {
+	t.Run("Rename should move the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+
+		if !_s.Load().Rename(_foo, _bar) {
+			t.Fatal("State was not found")
+		}
+
+		if l := _s.Load().States(); !slices.Equal(l, []string{"bar"}) {
+			t.Fatal("State was not moved")
+		}
+	})
+
+	t.Run("Rename should keep the state for the same name", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+
+		if !_s.Load().Rename(_foo, _foo) {
+			t.Fatal("State was removed")
+		}
+	})
+
+	t.Run("Rename should fail for empty targets", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_scan(_foo)
+
+		if _s.Load().Rename(_foo, nil) || _s.Load().Rename(_foo, []byte{}) {
+			t.Fatal("State was renamed")
+		}
+
+		if l := _s.Load().States(); !slices.Equal(l, []string{"foo"}) {
+			t.Fatal("State was removed")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package sub
+
+import (
+	"sync/atomic"
+)
+
+// Stats is a consistent snapshot of the statistics of a space.
+type Stats struct {
+	// Operations count.
+	Ops uint64
+	// Current count of signals.
+	Count uint64
+	// Current allocated memory.
+	Alloc uint64
+	// Current count of states.
+	States int
+	// Age of the oldest signal in milliseconds, zero if empty.
+	Oldest int64
+	// Age of the newest signal in milliseconds, zero if empty.
+	Newest int64
+	// Count of all dropped and evicted signals.
+	Dropped uint64
+	// Signals per second dropped by the last drop, since the drop before.
+	DropRate float64
+}
+
+// Stats returns a consistent snapshot of the statistics of the space.
+// Other than the public stat fields, it is safe for concurrent use.
+//
+// The space will not be locked for reading, but senders will be
+// blocked while the snapshot is taken.
+func (s *Space) Stats() (st Stats) {
+	s.tail.Lock()
+
+	now := s.clock.Now()
+
+	st.Ops = atomic.LoadUint64(&s.ops)
+	st.Count = atomic.LoadUint64(&s.StatCount)
+	st.Alloc = atomic.LoadUint64(&s.StatAlloc)
+	st.Dropped, st.DropRate = s.dropped, s.rate
+
+	if x := s.root.next; x != s.root {
+		st.Oldest, st.Newest = now-x.time, now-s.head.time
+	}
+
+	s.tail.Unlock()
+
+	s.states.RLock()
+	st.States = len(s.states.m)
+	s.states.RUnlock()
+
+	return
+}
+
+// TotalStats returns the statistics of all topics of the space summed up.
+// The ages are those of the oldest and the newest signal of all topics.
+// Each topic is snapshotted on its own, so the sum is not consistent.
+func (s *Space) TotalStats() (st Stats) {
+	var seen bool
+
+	for _, t := range append(s.named(), s.topics.space) {
+		v := t.Stats()
+
+		st.Ops += v.Ops
+		st.Count += v.Count
+		st.Alloc += v.Alloc
+		st.States += v.States
+		st.Dropped += v.Dropped
+		st.DropRate += v.DropRate
+
+		if v.Count > 0 {
+			st.Oldest = max(st.Oldest, v.Oldest)
+
+			if !seen || v.Newest < st.Newest {
+				st.Newest, seen = v.Newest, true
+			}
+		}
+	}
+
+	return
+}
+
+// Rated updates the drop rate with the signals dropped since the given
+// count of dropped signals, at the given time.
+//
+// The space and its tail must be locked.
+func (s *Space) rated(dropped uint64, now int64) {
+	if s.last > 0 && now > s.last {
+		s.rate = float64(s.dropped-dropped) * 1e3 / float64(now-s.last)
+	}
+
+	s.last = now
+}
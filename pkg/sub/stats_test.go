@@ -0,0 +1,100 @@
+package sub
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Stats should return the signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+		_tick(10)
+		s.Send(_bar)
+		_tick(5)
+
+		_scan(_foo)
+
+		st := s.Stats()
+
+		if st.Count != 2 || st.Alloc != 6 || st.States != 1 {
+			t.Fatal("Stats are not correct")
+		}
+
+		if st.Oldest != 15 || st.Newest != 5 {
+			t.Fatal("Ages are not correct")
+		}
+
+		if st.Ops != 2 {
+			t.Fatal("Ops are not correct")
+		}
+	})
+
+	t.Run("TotalStats should sum up all topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Send(_foo)
+		_tick(10)
+		s.SendTopic("foo", _bar)
+		s.SendTopic("foo", _bar)
+		_tick(5)
+
+		st := s.TotalStats()
+
+		if st.Count != 3 || st.Alloc != 9 || st.Ops != 3 {
+			t.Fatal("Stats are not correct")
+		}
+
+		if st.Oldest != 15 || st.Newest != 5 {
+			t.Fatal("Ages are not correct")
+		}
+	})
+
+	t.Run("TotalStats should return the newest age of all topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		for i := 0; i < 8; i++ {
+			s.SendTopic(strconv.Itoa(i), _foo)
+			_tick(10)
+		}
+
+		s.SendTopic("foo", _bar)
+
+		if st := s.TotalStats(); st.Oldest != 80 || st.Newest != 0 {
+			t.Fatal("Ages are not correct")
+		}
+	})
+
+	t.Run("Stats should return the drop rate", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.Drop(Infinite)
+
+		for i := 1; i <= 4; i++ {
+			_send(byte(i))
+		}
+
+		_tick(2000)
+
+		_drop()
+
+		st := s.Stats()
+
+		if st.Dropped != 4 || st.DropRate != 2 {
+			t.Fatal("Drop rate is not correct")
+		}
+
+		if st.Oldest != 0 || st.Newest != 0 {
+			t.Fatal("Ages are not correct")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package sub
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// Topic returns the topic with the given name. If the topic does not exist,
+// it will be created. The empty name stands for the default topic, which is
+// the space returned by NewSpace itself.
+//
+// Every topic has its own chain of signals and its own namespace of states.
+// All topics of a space share the same internal clock.
+func (s *Space) Topic(name string) *Space {
+	if name == "" {
+		return s.topics.space
+	}
+
+	s.topics.RLock()
+	t, ok := s.topics.m[name]
+	s.topics.RUnlock()
+
+	if ok {
+		return t
+	}
+
+	s.topics.Lock()
+	defer s.topics.Unlock()
+
+	// check again, the topic may have been created meanwhile
+	if t, ok = s.topics.m[name]; !ok {
+		d := s.topics.space
+
+		t = newSpace(s.clock, s.topics, name)
+		t.journal = d.journal
+
+		d.tail.Lock()
+		t.limit, t.compact, t.window = d.limit, d.compact, d.window
+		d.tail.Unlock()
+
+		s.topics.m[name] = t
+	}
+
+	return t
+}
+
+// LookupTopic returns the topic with the given name, without creating it.
+// It reports whether the topic exists. The default topic always exists.
+func (s *Space) LookupTopic(name string) (*Space, bool) {
+	if name == "" {
+		return s.topics.space, true
+	}
+
+	s.topics.RLock()
+	defer s.topics.RUnlock()
+
+	t, ok := s.topics.m[name]
+
+	return t, ok
+}
+
+// Topics returns the sorted names of all existing topics,
+// without the default topic.
+func (s *Space) Topics() []string {
+	s.topics.RLock()
+
+	l := make([]string, 0, len(s.topics.m))
+
+	for k := range s.topics.m {
+		l = append(l, k)
+	}
+
+	s.topics.RUnlock()
+
+	slices.Sort(l)
+
+	return l
+}
+
+// Named returns all existing topics, without the default topic.
+func (s *Space) named() []*Space {
+	s.topics.RLock()
+	defer s.topics.RUnlock()
+
+	l := make([]*Space, 0, len(s.topics.m))
+
+	for _, t := range s.topics.m {
+		l = append(l, t)
+	}
+
+	return l
+}
+
+// SendTopic will append the given signal at the end of the given topic.
+// The signal can be sent with additional attributes.
+//
+// SendTopic will return the current topics operations count
+// as a timestamp of the topics internal signal state.
+func (s *Space) SendTopic(topic string, data []byte, attrs ...Attr) uint64 {
+	return s.Topic(topic).Send(data, attrs...)
+}
+
+// ScanTopic scans all signals of the given topic since the beginning
+// or since the given state. States of different topics are independent.
+//
+// ScanTopic will return the current topics operations count
+// as a timestamp of the topics internal signal state.
+func (s *Space) ScanTopic(ch chan<- []byte, topic string, state []byte) uint64 {
+	return s.Topic(topic).Scan(ch, state)
+}
+
+// DropTopic invalidates all signals of the given topic
+// older than the given retention time. Unknown topics
+// will not be created.
+//
+// DropTopic will return the current topics operations count
+// as a timestamp of the topics internal signal state.
+func (s *Space) DropTopic(topic string, retention int64) uint64 {
+	t, ok := s.LookupTopic(topic)
+
+	if !ok {
+		return atomic.LoadUint64(&s.ops)
+	}
+
+	o := t.drop(retention)
+
+	s.truncate()
+
+	return o
+}
@@ -0,0 +1,204 @@
+package sub
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTopic(t *testing.T) {
+	t.Run("Topic should return the default topic for no name", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		if s.Topic("") != s {
+			t.Fatal("Topic is not the default topic")
+		}
+
+		if s.Topic("foo").Topic("") != s {
+			t.Fatal("Topic is not the default topic")
+		}
+	})
+
+	t.Run("Topic should return the same topic for a name", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		if s.Topic("foo") != s.Topic("foo") {
+			t.Fatal("Topic is not the same")
+		}
+
+		if s.Topic("foo") == s.Topic("bar") {
+			t.Fatal("Topic is the same")
+		}
+	})
+
+	t.Run("Topic should share the clock", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		if s.Topic("foo").clock != s.clock {
+			t.Fatal("Clock is not shared")
+		}
+	})
+}
+
+func TestTopics(t *testing.T) {
+	t.Run("Topics should return all topic names sorted", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.SendTopic("foo", _foo)
+		s.SendTopic("bar", _bar)
+
+		if !slices.Equal(s.Topics(), []string{"bar", "foo"}) {
+			t.Fatal("Topics are not correct")
+		}
+	})
+}
+
+func TestLookupTopic(t *testing.T) {
+	t.Run("LookupTopic should not create a topic", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		if _, ok := s.LookupTopic("foo"); ok || len(s.Topics()) != 0 {
+			t.Fatal("Topic was created")
+		}
+
+		if v, ok := s.LookupTopic(""); !ok || v != s {
+			t.Fatal("Default topic was not found")
+		}
+
+		if v, ok := s.LookupTopic(s.Topic("bar").topic); !ok || v != s.Topic("bar") {
+			t.Fatal("Topic was not found")
+		}
+	})
+}
+
+func TestSendTopic(t *testing.T) {
+	t.Run("SendTopic should only append to the topic", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.SendTopic("foo", []byte{1})
+
+		if s.head != s.root {
+			t.Fatal("Default topic was changed")
+		}
+
+		if s.Topic("foo").head.data[0] != 1 {
+			t.Fatal("Signal data is not correct")
+		}
+	})
+}
+
+func TestScanTopic(t *testing.T) {
+	t.Run("ScanTopic should only return signals of the topic", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		s.SendTopic("foo", []byte{2})
+		s.SendTopic("bar", []byte{3})
+
+		v := _scanTopic("foo", _foo)
+
+		if len(v) != 1 || v[0] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("ScanTopic should have its own states", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		s.SendTopic("foo", []byte{2})
+
+		_scan(_foo)
+
+		if len(_scanTopic("foo", _foo)) != 1 {
+			t.Fatal("State is shared")
+		}
+
+		if _, ok := s.Topic("foo").states.m[string(_foo)]; !ok {
+			t.Fatal("State was not saved")
+		}
+	})
+}
+
+func TestDropTopic(t *testing.T) {
+	t.Run("DropTopic should only drop the topic", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		s.SendTopic("foo", []byte{2})
+		s.DropTopic("foo", _now)
+
+		if s.head == s.root {
+			t.Fatal("Default topic was dropped")
+		}
+
+		if x := s.Topic("foo"); x.head != x.root {
+			t.Fatal("Topic was not dropped")
+		}
+	})
+
+	t.Run("DropTopic should not create the topic", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		s.DropTopic("foo", _now)
+
+		if len(s.Topics()) != 0 {
+			t.Fatal("Topic was created")
+		}
+	})
+
+	t.Run("Drop should drop all topics", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		s := _s.Load()
+
+		_send(1)
+
+		s.SendTopic("foo", []byte{2})
+
+		_drop()
+
+		if s.head != s.root {
+			t.Fatal("Default topic was not dropped")
+		}
+
+		if x := s.Topic("foo"); x.head != x.root {
+			t.Fatal("Topic was not dropped")
+		}
+	})
+}
+
+func _scanTopic(topic string, b []byte) []byte {
+	bs := make([]byte, 0)
+	ch := make(chan []byte)
+
+	go _s.Load().ScanTopic(ch, topic, b)
+
+	for v := range ch {
+		bs = append(bs, v[0])
+	}
+
+	return bs
+}
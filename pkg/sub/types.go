@@ -2,27 +2,58 @@ package sub
 
 import (
 	"sync"
+	"time"
 )
 
 // A space represents a chronological order of signals.
 // All structure fields are not safe for concurrent usage
 // and the space must be locked for every access to them.
 //
+// Senders only lock the tail of the space, so that signals
+// can be appended while the space is locked for reading.
+// The tail must always be locked before the space.
+//
 // Public stats are not safe for concurrent usage.
 //
-// A space should never be reused.
+// A space can not be reused after it was closed.
 type Space struct {
 	sync.RWMutex
+	// Lock of the tail, held by senders while appending.
+	tail sync.Mutex
 	// Current count of signals.
 	StatCount uint64
 	// Current allocated memory.
 	StatAlloc uint64
-	// Current space time.
-	now int64
+	// Clock of the space, shared by all topics.
+	clock Clock
+	// Retention time of automatic drops.
+	retention int64
+	// Interval of automatic drops, zero for none.
+	interval time.Duration
 	// Every time a space altering operation happens,
 	// the ops value will be increased by one.
 	// The ops value will never be decreased.
 	ops uint64
+	// Sequence number of the last appended signal.
+	seq uint64
+	// Count of signals with their own time to live.
+	ttls uint64
+	// Key-based compaction mode.
+	compact bool
+	// Latest signal per key, if compacted.
+	keys map[string]*signal
+	// Count of signals superseded by a newer signal with the same key.
+	stale uint64
+	// Dedup window in milliseconds, zero for none.
+	window int64
+	// Latest signal per dedup key, if deduplicated.
+	seen map[dedup]*signal
+	// Count of all dropped and evicted signals.
+	dropped uint64
+	// Time of the last drop.
+	last int64
+	// Signals per second dropped by the last drop.
+	rate float64
 	// The root is a performance optimization for faster appending new signals.
 	// All signals will be chained from it. Because of its infinite signal time,
 	// it will never be dropped.
@@ -30,10 +61,48 @@ type Space struct {
 	// The head will always point to the last signal of the chain.
 	// It will point to the root signal initially.
 	head *signal
-	// Pool of cached signal structures.
-	pool sync.Pool
+	// Closed and reset on the next append, if any watcher waits.
+	wake chan struct{}
+	// Closed and reset on the next drop, if any sender waits.
+	room chan struct{}
+	// Limit of the space.
+	limit limit
 	// Storage of scan states.
 	states *states
+	// Storage of topics, shared by all topics.
+	topics *topics
+	// Name of the topic, empty for the default topic.
+	topic string
+	// Journal for durability, nil if memory only.
+	journal *journal
+}
+
+// Topics is a lockable storage for named topics.
+// The underlying map is not safe for concurrent usage
+// and the topics must be locked for every access to it.
+//
+// Each topic is a space with its own chain and states,
+// sharing the internal clock of the default topic.
+type topics struct {
+	sync.RWMutex
+	// Default topic.
+	space *Space
+	// Underlying map.
+	m map[string]*Space
+	// Closed if the space was closed.
+	done chan struct{}
+	// Hooks of all topics.
+	hooks Hooks
+	// Limit of all topics together, its policy is not used.
+	total limit
+	// Current count of signals of all topics.
+	count uint64
+	// Current allocated memory of all topics.
+	alloc uint64
+	// Lock of room.
+	wait sync.Mutex
+	// Closed and reset on the next drop of any topic, if any sender waits.
+	room chan struct{}
 }
 
 // States is a lockable storage for scan states.
@@ -48,6 +117,19 @@ type states struct {
 	sync.RWMutex
 	// Underlying map.
 	m map[string]*signal
+	// Unacknowledged leases per state.
+	leases map[string]lease
+}
+
+// A lease is a batch of signals delivered for a state,
+// that has not been acknowledged yet.
+type lease struct {
+	// Last signal of the batch.
+	x *signal
+	// Sequence number of the last signal.
+	q uint64
+	// Time of expiry.
+	expires int64
 }
 
 // A signal represents a received data package.
@@ -56,11 +138,40 @@ type states struct {
 //
 // A signal with nil as value for data or next has been dropped
 // and will be consumed by the garbage collector soon.
+//
+// The next and stale fields may change while the space is locked
+// for reading and must only be accessed atomically then.
 type signal struct {
 	// Time of receiving.
 	time int64
+	// Sequence number inside its topic.
+	seq uint64
+	// Time to live, zero for the retention time of Drop.
+	ttl int64
+	// Header of key value pairs, nil for none.
+	header map[string]string
+	// Key for compaction, empty for none.
+	key string
+	// Producer id for deduplication, empty for none.
+	id string
+	// Superseded by a newer signal with the same key, 1 if so.
+	stale uint32
 	// Received data.
 	data []byte
 	// Next signal.
 	next *signal
 }
+
+// A message is a signal as delivered with its metadata.
+type Message struct {
+	// Offset of the signal inside its topic.
+	Offset uint64
+	// Time of receiving in milliseconds since epoch.
+	Time int64
+	// Header of key value pairs, nil for none.
+	Header map[string]string
+	// Key for compaction, empty for none.
+	Key string
+	// Received data.
+	Data []byte
+}
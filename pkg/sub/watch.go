@@ -0,0 +1,98 @@
+package sub
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Watch all signals since the beginning or since the given state
+// and keep on watching for new signals, until the given context is
+// done or the space is closed. The given channel will be closed.
+// States are handled the same way as by Scan, but the state will be
+// advanced with every signal delivered to the channel.
+//
+// The space will not be locked while waiting for new signals or
+// while waiting for the channel.
+//
+// This should be run as a goroutine, since this is a blocking call.
+//
+// Watch will return the current spaces operations count
+// as a timestamp of the spaces internal signal state.
+func (s *Space) Watch(ctx context.Context, ch chan<- []byte, state []byte) uint64 {
+	defer close(ch)
+
+	c := s.cursor(state, false)
+
+	s.save(state, c.x)
+
+	for {
+		x, v, ok := c.peek()
+
+		// wait for new signals on head
+		if !ok {
+			select {
+			case <-s.wait(c.x, c.q):
+			case <-s.topics.done:
+				return atomic.LoadUint64(&s.ops)
+			case <-ctx.Done():
+				return atomic.LoadUint64(&s.ops)
+			}
+
+			continue
+		}
+
+		select {
+		case ch <- v.data:
+			c.seek(x, v)
+		case <-s.topics.done:
+			return atomic.LoadUint64(&s.ops)
+		case <-ctx.Done():
+			return atomic.LoadUint64(&s.ops)
+		}
+
+		s.save(state, x)
+	}
+}
+
+// Wait returns a channel that will be closed with the next append,
+// or an already closed channel, if the given signal is not the head
+// anymore.
+func (s *Space) wait(x *signal, q uint64) <-chan struct{} {
+	s.tail.Lock()
+	defer s.tail.Unlock()
+
+	n := s.next(x, q)
+
+	// skip superseded signals
+	for n.superseded() {
+		n = n.link()
+	}
+
+	if n != s.root {
+		return closed
+	}
+
+	if s.wake == nil {
+		s.wake = make(chan struct{})
+	}
+
+	return s.wake
+}
+
+// Wakeup wakes all waiting watchers.
+//
+// The tail must be locked.
+func (s *Space) wakeup() {
+	if s.wake != nil {
+		close(s.wake)
+
+		s.wake = nil
+	}
+}
+
+// An always closed channel.
+var closed = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
@@ -0,0 +1,122 @@
+package sub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	t.Run("Watch should return existing signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		v := _watch(_foo, 2)
+
+		if len(v) != 2 || v[0] != 1 || v[1] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Watch should return new signals", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			_send(2)
+		}()
+
+		v := _watch(_foo, 2)
+
+		if len(v) != 2 || v[1] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Watch should advance the state", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+		_send(2)
+
+		_watch(_foo, 1)
+
+		v := _scan(_foo)
+
+		if len(v) != 1 || v[0] != 2 {
+			t.Fatal("State was not advanced")
+		}
+	})
+
+	t.Run("Watch should continue after a drop", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		_send(1)
+
+		_watch(_foo, 1)
+
+		_tick(1)
+
+		_drop()
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			_send(2)
+		}()
+
+		v := _watch(_foo, 1)
+
+		if len(v) != 1 || v[0] != 2 {
+			t.Fatal("Data is not correct")
+		}
+	})
+
+	t.Run("Watch should stop if cancelled", func(t *testing.T) {
+		t.Cleanup(_cleanup)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		ch := make(chan []byte)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+
+			cancel()
+		}()
+
+		_s.Load().Watch(ctx, ch, _foo)
+
+		if _, ok := <-ch; ok {
+			t.Fatal("Channel was not closed")
+		}
+	})
+}
+
+func _watch(b []byte, n int) []byte {
+	bs := make([]byte, 0)
+	ch := make(chan []byte)
+
+	done := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() { _s.Load().Watch(ctx, ch, b); close(done) }()
+
+	for v := range ch {
+		if bs = append(bs, v[0]); len(bs) == n {
+			break
+		}
+	}
+
+	cancel()
+
+	<-done
+
+	return bs
+}
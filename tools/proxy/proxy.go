@@ -6,16 +6,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/cuhsat/subspace/internal/app/ss"
+	"github.com/cuhsat/subspace/internal/pkg/wire"
 )
 
 const mime = "application/json"
 const host = "localhost"
 const port = ":8080"
 
+// Prefix of HTTP headers carrying signal headers.
+const prefix = "X-Signal-"
+
 type signals struct {
 	Signals [][]byte
+	Headers []map[string]string
+	Token   string `json:",omitempty"`
 }
 
 func main() {
@@ -29,7 +37,7 @@ func main() {
 		case http.MethodPost:
 			code = send(c, w, r)
 		case http.MethodGet:
-			code = scan(c, w, r)
+			code = scan(w, r)
 		}
 
 		if code != http.StatusOK {
@@ -49,24 +57,64 @@ func send(c *ss.Channel, w http.ResponseWriter, r *http.Request) int {
 		return http.StatusInternalServerError
 	}
 
-	c.Send(b)
+	h := make(map[string]string)
+
+	for k := range r.Header {
+		if name, ok := strings.CutPrefix(k, prefix); ok {
+			h[strings.ToLower(name)] = r.Header.Get(k)
+		}
+	}
+
+	c.SendHeader(b, h)
 
 	return http.StatusOK
 }
 
-func scan(c *ss.Channel, w http.ResponseWriter, r *http.Request) int {
-	ch := make(chan []byte)
+// Scan responds with the signals of the state given by the path.
+// The query parameters count and bytes limit the signals of a page,
+// the token of a page will then continue with the next page.
+func scan(w http.ResponseWriter, r *http.Request) int {
+	// own channel, as limits differ per request
+	c := ss.NewChannel(host)
+
+	defer c.Close()
+
+	q := r.URL.Query()
+
+	for k, v := range map[string]*uint64{"count": &c.Count, "bytes": &c.Size, "token": &c.Offset} {
+		if !q.Has(k) {
+			continue
+		}
+
+		n, err := strconv.ParseUint(q.Get(k), 10, 64)
+
+		if err != nil {
+			return http.StatusBadRequest
+		}
+
+		*v = n
+	}
+
+	ch := make(chan *wire.Frame)
 
 	var state []byte
 	if len(r.URL.Path) > 0 {
 		state = []byte(r.URL.Path)
 	}
 
-	go c.Scan(ch, state)
+	go c.ScanFrames(ch, state)
 
 	var s signals
-	for x := range ch {
-		s.Signals = append(s.Signals, x)
+	var next uint64
+	for f := range ch {
+		s.Signals = append(s.Signals, f.Data)
+		s.Headers = append(s.Headers, f.Header)
+
+		next = max(next, f.Offset+1)
+	}
+
+	if next > 1 {
+		s.Token = strconv.FormatUint(next, 10)
 	}
 
 	w.Header().Set("Content-Type", mime)
@@ -21,6 +21,12 @@ echo "bar" | $GO_RUN $CLIENT $HOST $NAME
 
 sleep 1s
 
+curl -H "X-Signal-Type: text/plain" -d "baz" $HOST:$PORT/
+
+sleep 1s
+
 curl $HOST:$PORT/test
 
+curl "$HOST:$PORT/page?count=1"
+
 killall -INT main proxy
@@ -2,11 +2,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
-	"io"
 	"net"
+	"os"
 	"time"
 
+	"github.com/cuhsat/subspace/internal/app/subspace"
 	"github.com/cuhsat/subspace/internal/pkg/sys"
 )
 
@@ -57,8 +59,18 @@ func (c *cache) sync() (b []byte, err error) {
 	mt := fi.ModTime()
 
 	if mt.After(c.time) || c.time.IsZero() {
+		var r subspace.Report
+
+		if b, err = os.ReadFile(sys.Stats.Name()); err != nil {
+			return
+		}
+
+		if err = json.Unmarshal(b, &r); err != nil {
+			return
+		}
+
 		c.time = mt
-		c.data, err = io.ReadAll(sys.Stats)
+		c.data, err = json.Marshal(r)
 	}
 
 	b = c.data